@@ -25,8 +25,17 @@ Allowed interval:
 
 * custom start time and repeat duration, example:
 	- 23:00@daily, will repeated at 23:00 every day
-	- 23:00@weekly, will repeated at 23:00 every week
+	- 23:00@weekly, will repeated at 23:00 every week (in the same day of week with first activation)
+	- 23:00@monthly, will repeated at 23:00 every month (in the same day of month with first activation)
 	- 23:00@10s, will repeated at 23:00 and next repeat every 10 seconds
+
+* standard cron expression with 5 fields (minute, hour, day of month, month, day of week)
+or 6 fields (with second in first field), support list, range, step, name, and L (last day), example:
+	- 0 2 1 * *, will repeated at 02:00 in the first day of every month
+	- 30 17 * * MON-FRI, will repeated at 17:30 every weekday
+	- 0 0 L * *, will repeated at 00:00 in the last day of every month
+	- 0 9 * * 5L, will repeated at 09:00 in the last friday of every month
+	- @hourly, @daily, @weekly, @monthly, @yearly
 */
 func CronJobKeyToString(jobName, args, interval string) string {
 	return CronJobKey{
@@ -0,0 +1,34 @@
+package candishared
+
+import "time"
+
+// RateLimit task queue worker token bucket policy for limit job dispatch rate,
+// bucket is refilled with Limit tokens every Interval and each dispatched job take one token
+type RateLimit struct {
+	// Limit max number of jobs dispatched in each interval, rate limit is disabled if zero
+	Limit int
+	// Interval refill period of bucket, default is 1 second
+	Interval time.Duration
+	// Burst max tokens in bucket (max jobs dispatched at once after idle), default is Limit
+	Burst int
+}
+
+// Rate number of tokens refilled per second
+func (r RateLimit) Rate() float64 {
+	interval := r.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	return float64(r.Limit) / interval.Seconds()
+}
+
+// BucketSize max tokens in bucket
+func (r RateLimit) BucketSize() float64 {
+	if r.Burst > 0 {
+		return float64(r.Burst)
+	}
+	if r.Limit > 0 {
+		return float64(r.Limit)
+	}
+	return 1
+}
@@ -0,0 +1,16 @@
+package candishared
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	assert.Equal(t, 10.0, RateLimit{Limit: 10}.Rate())
+	assert.Equal(t, 0.5, RateLimit{Limit: 30, Interval: time.Minute}.Rate())
+	assert.Equal(t, 10.0, RateLimit{Limit: 10}.BucketSize())
+	assert.Equal(t, 3.0, RateLimit{Limit: 10, Burst: 3}.BucketSize())
+	assert.Equal(t, 1.0, RateLimit{}.BucketSize())
+}
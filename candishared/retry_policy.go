@@ -0,0 +1,67 @@
+package candishared
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryBackoffStrategy type
+type RetryBackoffStrategy string
+
+const (
+	// RetryBackoffConstant delay between retry is always same
+	RetryBackoffConstant RetryBackoffStrategy = "constant"
+	// RetryBackoffLinear delay between retry is increased linearly (delay * retries)
+	RetryBackoffLinear RetryBackoffStrategy = "linear"
+	// RetryBackoffExponential delay between retry is increased exponentially (delay * multiplier^(retries-1))
+	RetryBackoffExponential RetryBackoffStrategy = "exponential"
+
+	defaultRetryBackoffMultiplier = 2
+)
+
+// RetryPolicy task queue worker policy for calculate delay between retry
+type RetryPolicy struct {
+	Strategy RetryBackoffStrategy
+	// Delay base delay between retry
+	Delay time.Duration
+	// MaxDelay cap of delay between retry, ignored if zero
+	MaxDelay time.Duration
+	// Multiplier for exponential strategy, default is 2
+	Multiplier float64
+	// Jitter randomize delay between half and full of calculated delay
+	Jitter bool
+}
+
+// NextDelay calculate delay before next retry from given current retries (start from 1)
+func (r RetryPolicy) NextDelay(retries int) time.Duration {
+	if retries < 1 {
+		retries = 1
+	}
+
+	delay := float64(r.Delay)
+	switch r.Strategy {
+	case RetryBackoffLinear:
+		delay *= float64(retries)
+	case RetryBackoffExponential:
+		multiplier := r.Multiplier
+		if multiplier <= 0 {
+			multiplier = defaultRetryBackoffMultiplier
+		}
+		delay *= math.Pow(multiplier, float64(retries-1))
+	}
+
+	if r.MaxDelay > 0 && delay > float64(r.MaxDelay) {
+		delay = float64(r.MaxDelay)
+	}
+
+	result := time.Duration(math.MaxInt64)
+	if delay < math.MaxInt64 {
+		result = time.Duration(delay)
+	}
+	if r.Jitter && result > 1 {
+		half := result / 2
+		result = half + time.Duration(rand.Int63n(int64(result-half)))
+	}
+	return result
+}
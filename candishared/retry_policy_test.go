@@ -0,0 +1,57 @@
+package candishared
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicyNextDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  RetryPolicy
+		retries int
+		want    time.Duration
+	}{
+		{
+			name:    "Testcase #1: constant",
+			policy:  RetryPolicy{Strategy: RetryBackoffConstant, Delay: time.Second},
+			retries: 5, want: time.Second,
+		},
+		{
+			name:    "Testcase #2: linear",
+			policy:  RetryPolicy{Strategy: RetryBackoffLinear, Delay: time.Second},
+			retries: 3, want: 3 * time.Second,
+		},
+		{
+			name:    "Testcase #3: exponential with default multiplier",
+			policy:  RetryPolicy{Strategy: RetryBackoffExponential, Delay: time.Second},
+			retries: 4, want: 8 * time.Second,
+		},
+		{
+			name:    "Testcase #4: exponential with max delay",
+			policy:  RetryPolicy{Strategy: RetryBackoffExponential, Delay: time.Second, Multiplier: 3, MaxDelay: time.Minute},
+			retries: 10, want: time.Minute,
+		},
+		{
+			name:    "Testcase #5: exponential overflow",
+			policy:  RetryPolicy{Strategy: RetryBackoffExponential, Delay: time.Hour},
+			retries: 1000, want: time.Duration(1<<63 - 1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.policy.NextDelay(tt.retries))
+		})
+	}
+
+	t.Run("Testcase #6: with jitter", func(t *testing.T) {
+		policy := RetryPolicy{Strategy: RetryBackoffConstant, Delay: 10 * time.Second, Jitter: true}
+		for i := 0; i < 100; i++ {
+			delay := policy.NextDelay(1)
+			assert.True(t, delay >= 5*time.Second && delay < 10*time.Second)
+		}
+	})
+}
@@ -0,0 +1,349 @@
+package candiutils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule describe activation time of recurring job
+type CronSchedule interface {
+	// Next return next activation time after given time, return zero time if no activation time found
+	Next(t time.Time) time.Time
+}
+
+type (
+	cronExpression struct {
+		second, minute, hour, dom, month, dow uint64
+		// lastDom is set when day of month contain L, lastDow is bits of day of week with L suffix (last weekday of month)
+		lastDom, lastDow uint64
+	}
+
+	cronInterval struct {
+		interval time.Duration
+	}
+
+	cronField struct {
+		min, max uint
+		names    map[string]uint
+	}
+)
+
+const cronStarBit = 1 << 63
+
+var (
+	cronSeconds    = cronField{min: 0, max: 59}
+	cronMinutes    = cronField{min: 0, max: 59}
+	cronHours      = cronField{min: 0, max: 23}
+	cronDayOfMonth = cronField{min: 1, max: 31}
+	cronMonths     = cronField{min: 1, max: 12, names: map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	cronDayOfWeek = cronField{min: 0, max: 7, names: map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+
+	cronDescriptors = map[string]string{
+		"@yearly":   "0 0 0 1 1 *",
+		"@annually": "0 0 0 1 1 *",
+		"@monthly":  "0 0 0 1 * *",
+		"@weekly":   "0 0 0 * * 0",
+		"@daily":    "0 0 0 * * *",
+		"@midnight": "0 0 0 * * *",
+		"@hourly":   "0 0 * * * *",
+	}
+)
+
+// ParseCronExpression parse standard cron expression with 5 fields (minute, hour, day of month, month, day of week)
+// or 6 fields (with second in first field). Each field support wildcard (* or ?), list (1,2), range (1-5),
+// step (*/5 or 1-30/5), and name of month or day of week (JAN-DEC, SUN-SAT).
+// Last day of month can be written as L in day of month, and last weekday of month as <day>L in day of week (5L or FRIL).
+// Also support descriptors @yearly, @monthly, @weekly, @daily, @hourly, and @every <duration>
+func ParseCronExpression(expr string) (CronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, errors.New("empty cron expression")
+	}
+
+	if strings.HasPrefix(expr, "@every ") {
+		interval, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(expr, "@every ")))
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron interval: %v", err)
+		}
+		if interval <= 0 {
+			return nil, errors.New("cron interval must greater than zero")
+		}
+		return &cronInterval{interval: interval}, nil
+	}
+	if descriptor, ok := cronDescriptors[strings.ToLower(expr)]; ok {
+		expr = descriptor
+	}
+
+	fields := strings.Fields(expr)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("invalid cron expression '%s': expected 5 or 6 fields, found %d", expr, len(fields))
+	}
+
+	var (
+		schedule cronExpression
+		err      error
+	)
+	if fields[3], schedule.lastDom, err = extractLastDay(fields[3], nil); err != nil {
+		return nil, fmt.Errorf("invalid cron expression '%s': %v", expr, err)
+	}
+	if fields[5], schedule.lastDow, err = extractLastDay(fields[5], &cronDayOfWeek); err != nil {
+		return nil, fmt.Errorf("invalid cron expression '%s': %v", expr, err)
+	}
+	for i, f := range []struct {
+		field cronField
+		bits  *uint64
+	}{
+		{cronSeconds, &schedule.second},
+		{cronMinutes, &schedule.minute},
+		{cronHours, &schedule.hour},
+		{cronDayOfMonth, &schedule.dom},
+		{cronMonths, &schedule.month},
+		{cronDayOfWeek, &schedule.dow},
+	} {
+		// field only contain last day
+		if fields[i] == "" {
+			continue
+		}
+		if *f.bits, err = f.field.parse(fields[i]); err != nil {
+			return nil, fmt.Errorf("invalid cron expression '%s': %v", expr, err)
+		}
+	}
+
+	// sunday can be written as 0 or 7
+	if schedule.dow&(1<<7) > 0 {
+		schedule.dow = (schedule.dow | 1) &^ (1 << 7)
+	}
+	if schedule.lastDow&(1<<7) > 0 {
+		schedule.lastDow = (schedule.lastDow | 1) &^ (1 << 7)
+	}
+	return &schedule, nil
+}
+
+// extractLastDay remove last day part from list, L for day of month (dow is nil) or <day>L for day of week,
+// return remaining list and bits of last day
+func extractLastDay(expr string, dow *cronField) (rest string, bits uint64, err error) {
+	var parts []string
+	for _, part := range strings.Split(expr, ",") {
+		if !strings.HasSuffix(strings.ToUpper(part), "L") {
+			parts = append(parts, part)
+			continue
+		}
+
+		value := part[:len(part)-1]
+		switch {
+		case dow == nil && value == "":
+			bits |= 1
+		case dow != nil && value != "":
+			v, err := dow.parseValue(value)
+			if err != nil {
+				return "", 0, err
+			}
+			bits |= 1 << v
+		default:
+			return "", 0, fmt.Errorf("invalid last day '%s'", part)
+		}
+	}
+	return strings.Join(parts, ","), bits, nil
+}
+
+func (f cronField) parse(expr string) (bits uint64, err error) {
+	for _, part := range strings.Split(expr, ",") {
+		b, err := f.parseRange(part)
+		if err != nil {
+			return 0, err
+		}
+		bits |= b
+	}
+	return bits, nil
+}
+
+func (f cronField) parseRange(expr string) (uint64, error) {
+	rangeAndStep := strings.Split(expr, "/")
+	if len(rangeAndStep) > 2 {
+		return 0, fmt.Errorf("invalid step '%s'", expr)
+	}
+
+	var start, end, step uint = f.min, f.max, 1
+	var extra uint64
+	switch lowAndHigh := strings.Split(rangeAndStep[0], "-"); {
+	case rangeAndStep[0] == "*" || rangeAndStep[0] == "?":
+		if len(rangeAndStep) == 1 {
+			extra = cronStarBit
+		}
+	case len(lowAndHigh) == 1:
+		v, err := f.parseValue(lowAndHigh[0])
+		if err != nil {
+			return 0, err
+		}
+		start = v
+		if len(rangeAndStep) == 1 {
+			end = v
+		}
+	case len(lowAndHigh) == 2:
+		var err error
+		if start, err = f.parseValue(lowAndHigh[0]); err != nil {
+			return 0, err
+		}
+		if end, err = f.parseValue(lowAndHigh[1]); err != nil {
+			return 0, err
+		}
+	default:
+		return 0, fmt.Errorf("invalid range '%s'", expr)
+	}
+
+	if len(rangeAndStep) == 2 {
+		s, err := strconv.Atoi(rangeAndStep[1])
+		if err != nil || s <= 0 {
+			return 0, fmt.Errorf("invalid step '%s'", expr)
+		}
+		step = uint(s)
+	}
+	if start > end {
+		return 0, fmt.Errorf("invalid range '%s': start greater than end", expr)
+	}
+
+	var bits uint64
+	for i := start; i <= end; i += step {
+		bits |= 1 << i
+	}
+	return bits | extra, nil
+}
+
+func (f cronField) parseValue(expr string) (uint, error) {
+	if v, ok := f.names[strings.ToLower(expr)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(expr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value '%s'", expr)
+	}
+	if v < int(f.min) || v > int(f.max) {
+		return 0, fmt.Errorf("value '%s' out of range [%d-%d]", expr, f.min, f.max)
+	}
+	return uint(v), nil
+}
+
+// Next implement CronSchedule, search next activation time in the location of given time.
+// Activation time in the hour skipped by daylight saving time transition is moved to the hour after transition,
+// and activation time in the repeated hour is only activated once (except for every hour schedule)
+func (c *cronExpression) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Add(time.Second - time.Duration(t.Nanosecond())*time.Nanosecond)
+
+	// added flag is used to reset lower units to lowest value when higher unit changed
+	added := false
+	yearLimit := t.Year() + 5
+
+WRAP:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+
+	for 1<<uint(t.Month())&c.month == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+		}
+		t = t.AddDate(0, 1, 0)
+		if t.Month() == time.January {
+			goto WRAP
+		}
+	}
+
+	for !c.dayMatches(t) {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		}
+		t = t.AddDate(0, 0, 1)
+		if t.Day() == 1 {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Hour())&c.hour == 0 {
+		if !added {
+			added = true
+			t = t.Add(-time.Duration(t.Minute()*60+t.Second()) * time.Second)
+		}
+		skippedHour := (t.Hour() + 1) % 24
+		t = t.Add(time.Hour)
+		// hour skipped by daylight saving time transition, activate in the first hour after transition
+		if t.Hour() == (skippedHour+1)%24 && 1<<uint(skippedHour)&c.hour > 0 {
+			break
+		}
+		if t.Hour() == 0 {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Minute())&c.minute == 0 {
+		if !added {
+			added = true
+			t = t.Truncate(time.Minute)
+		}
+		hour := t.Hour()
+		t = t.Add(time.Minute)
+		if t.Minute() == 0 {
+			t = c.skipRepeatedHour(t, hour)
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Second())&c.second == 0 {
+		if !added {
+			added = true
+			t = t.Truncate(time.Second)
+		}
+		hour := t.Hour()
+		t = t.Add(time.Second)
+		if t.Second() == 0 {
+			if t.Minute() == 0 {
+				t = c.skipRepeatedHour(t, hour)
+			}
+			goto WRAP
+		}
+	}
+
+	return t
+}
+
+// skipRepeatedHour if hour is repeated by daylight saving time transition (wall clock turned back),
+// skip repeated hour so that job with specific hour only activated once
+func (c *cronExpression) skipRepeatedHour(t time.Time, previousHour int) time.Time {
+	if t.Hour() == previousHour && c.hour&cronStarBit == 0 {
+		return t.Add(time.Hour)
+	}
+	return t
+}
+
+// dayMatches if day of month or day of week is restricted (not wildcard), either one must match
+func (c *cronExpression) dayMatches(t time.Time) bool {
+	domMatch := 1<<uint(t.Day())&c.dom > 0 || (c.lastDom > 0 && t.AddDate(0, 0, 1).Day() == 1)
+	dowMatch := 1<<uint(t.Weekday())&c.dow > 0 || (1<<uint(t.Weekday())&c.lastDow > 0 && t.AddDate(0, 0, 7).Month() != t.Month())
+	if c.dom&cronStarBit > 0 || c.dow&cronStarBit > 0 {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// Next implement CronSchedule
+func (c *cronInterval) Next(t time.Time) time.Time {
+	// sub second interval is not aligned to whole second
+	if c.interval < time.Second {
+		return t.Add(c.interval)
+	}
+	return t.Add(c.interval - time.Duration(t.Nanosecond())*time.Nanosecond)
+}
@@ -0,0 +1,150 @@
+package candiutils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCronExpression(t *testing.T) {
+	base := time.Date(2021, time.March, 15, 10, 30, 15, 500, time.UTC) // monday
+
+	testCase := map[string]struct {
+		expr     string
+		expected time.Time
+		wantErr  bool
+	}{
+		"Testcase #1: every minute": {
+			expr: "* * * * *", expected: time.Date(2021, time.March, 15, 10, 31, 0, 0, time.UTC),
+		},
+		"Testcase #2: with second field": {
+			expr: "*/10 * * * * *", expected: time.Date(2021, time.March, 15, 10, 30, 20, 0, time.UTC),
+		},
+		"Testcase #3: range and step": {
+			expr: "0 9-17/4 * * *", expected: time.Date(2021, time.March, 15, 13, 0, 0, 0, time.UTC),
+		},
+		"Testcase #4: list of day of week name": {
+			expr: "0 8 * * SAT,SUN", expected: time.Date(2021, time.March, 20, 8, 0, 0, 0, time.UTC),
+		},
+		"Testcase #5: sunday as 7": {
+			expr: "0 8 * * 7", expected: time.Date(2021, time.March, 21, 8, 0, 0, 0, time.UTC),
+		},
+		"Testcase #6: month name and wrap year": {
+			expr: "0 0 1 JAN *", expected: time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC),
+		},
+		"Testcase #7: day of month or day of week": {
+			expr: "0 0 20 * MON", expected: time.Date(2021, time.March, 20, 0, 0, 0, 0, time.UTC),
+		},
+		"Testcase #8: 31th day skip short month": {
+			expr: "0 0 31 * *", expected: time.Date(2021, time.March, 31, 0, 0, 0, 0, time.UTC),
+		},
+		"Testcase #9: descriptor": {
+			expr: "@monthly", expected: time.Date(2021, time.April, 1, 0, 0, 0, 0, time.UTC),
+		},
+		"Testcase #10: every interval": {
+			expr: "@every 1h30m", expected: time.Date(2021, time.March, 15, 12, 0, 15, 0, time.UTC),
+		},
+		"Testcase #11: invalid total fields": {
+			expr: "* * *", wantErr: true,
+		},
+		"Testcase #12: value out of range": {
+			expr: "0 24 * * *", wantErr: true,
+		},
+		"Testcase #13: invalid range": {
+			expr: "0 10-5 * * *", wantErr: true,
+		},
+		"Testcase #14: never activated": {
+			expr: "0 0 30 FEB *", expected: time.Time{},
+		},
+		"Testcase #15: last day of month": {
+			expr: "0 2 L FEB *", expected: time.Date(2022, time.February, 28, 2, 0, 0, 0, time.UTC),
+		},
+		"Testcase #16: last weekday of month": {
+			expr: "0 0 * * 5L", expected: time.Date(2021, time.March, 26, 0, 0, 0, 0, time.UTC),
+		},
+		"Testcase #17: list with last day of month": {
+			expr: "0 0 1,L * *", expected: time.Date(2021, time.March, 31, 0, 0, 0, 0, time.UTC),
+		},
+		"Testcase #18: last sunday of month with name": {
+			expr: "0 0 * MAR SUNL", expected: time.Date(2021, time.March, 28, 0, 0, 0, 0, time.UTC),
+		},
+		"Testcase #19: invalid last day": {
+			expr: "0 0 5L * *", wantErr: true,
+		},
+		"Testcase #20: sub second interval": {
+			expr: "@every 500ms", expected: time.Date(2021, time.March, 15, 10, 30, 15, 500+int(500*time.Millisecond), time.UTC),
+		},
+		"Testcase #21: invalid interval": {
+			expr: "@every 0s", wantErr: true,
+		},
+	}
+
+	for name, tt := range testCase {
+		t.Run(name, func(t *testing.T) {
+			schedule, err := ParseCronExpression(tt.expr)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, schedule.Next(base))
+		})
+	}
+}
+
+func TestCronExpressionDaylightSavingTime(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	testCase := map[string]struct {
+		expr     string
+		base     time.Time
+		expected []time.Time
+	}{
+		"Testcase #1: skipped hour activated after transition": {
+			expr: "30 2 * * *", base: time.Date(2021, time.March, 13, 3, 0, 0, 0, loc),
+			expected: []time.Time{
+				time.Date(2021, time.March, 14, 3, 30, 0, 0, loc),
+				time.Date(2021, time.March, 15, 2, 30, 0, 0, loc),
+			},
+		},
+		"Testcase #2: repeated hour only activated once": {
+			expr: "30 1 * * *", base: time.Date(2021, time.November, 7, 0, 0, 0, 0, loc),
+			expected: []time.Time{
+				time.Date(2021, time.November, 7, 5, 30, 0, 0, time.UTC).In(loc),
+				time.Date(2021, time.November, 8, 1, 30, 0, 0, loc),
+			},
+		},
+		"Testcase #3: every hour schedule activated in repeated hour": {
+			expr: "0 * * * *", base: time.Date(2021, time.November, 7, 0, 30, 0, 0, loc),
+			expected: []time.Time{
+				time.Date(2021, time.November, 7, 5, 0, 0, 0, time.UTC).In(loc),
+				time.Date(2021, time.November, 7, 6, 0, 0, 0, time.UTC).In(loc),
+				time.Date(2021, time.November, 7, 7, 0, 0, 0, time.UTC).In(loc),
+			},
+		},
+		"Testcase #4: daily in timezone": {
+			expr: "0 9 * * *", base: time.Date(2021, time.March, 13, 12, 0, 0, 0, loc),
+			expected: []time.Time{
+				time.Date(2021, time.March, 14, 9, 0, 0, 0, loc),
+				time.Date(2021, time.March, 15, 9, 0, 0, 0, loc),
+			},
+		},
+	}
+
+	for name, tt := range testCase {
+		t.Run(name, func(t *testing.T) {
+			schedule, err := ParseCronExpression(tt.expr)
+			assert.NoError(t, err)
+
+			next := tt.base
+			for _, expected := range tt.expected {
+				next = schedule.Next(next)
+				assert.True(t, expected.Equal(next), "expected %v, got %v", expected, next)
+			}
+		})
+	}
+}
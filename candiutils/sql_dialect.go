@@ -0,0 +1,134 @@
+package candiutils
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SQLColumn column definition of sql table
+type SQLColumn struct {
+	Name, DataType string
+}
+
+// SQLDialect helper for create table & index and build query in sql database, support postgres and mysql
+type SQLDialect struct {
+	DB         *sql.DB
+	IsPostgres bool
+}
+
+// NewSQLDialect create sql dialect helper, dialect is detected from driver of given db (postgres if not mysql driver)
+func NewSQLDialect(db *sql.DB) *SQLDialect {
+	return &SQLDialect{
+		DB:         db,
+		IsPostgres: !strings.Contains(strings.ToLower(reflect.TypeOf(db.Driver()).String()), "mysql"),
+	}
+}
+
+// CreateTable create table if not exist and add new columns to existing table
+func (d *SQLDialect) CreateTable(tableName string, columns []SQLColumn) error {
+	definitions := make([]string, len(columns))
+	for i, col := range columns {
+		definitions[i] = col.Name + " " + col.DataType
+	}
+	query := "CREATE TABLE IF NOT EXISTS " + tableName + " (" + strings.Join(definitions, ", ") + ")"
+	if _, err := d.DB.Exec(query); err != nil {
+		return fmt.Errorf("failed when create table %s: %v", tableName, err)
+	}
+
+	query = "SELECT column_name FROM information_schema.columns WHERE table_name = ? AND table_schema = "
+	if d.IsPostgres {
+		query += "current_schema()"
+	} else {
+		query += "DATABASE()"
+	}
+	rows, err := d.DB.Query(d.Rebind(query), tableName)
+	if err != nil {
+		return fmt.Errorf("failed when get columns of table %s: %v", tableName, err)
+	}
+	defer rows.Close()
+
+	existingColumns := make(map[string]bool)
+	for rows.Next() {
+		var columnName string
+		rows.Scan(&columnName)
+		existingColumns[strings.ToLower(columnName)] = true
+	}
+
+	for _, col := range columns {
+		if existingColumns[col.Name] {
+			continue
+		}
+		if _, err := d.DB.Exec("ALTER TABLE " + tableName + " ADD COLUMN " + col.Name + " " + col.DataType); err != nil {
+			return fmt.Errorf("failed when add column %s to table %s: %v", col.Name, tableName, err)
+		}
+	}
+	return nil
+}
+
+// CreateIndex create index (named idx_<table>_<columns>) if not exist, error other than existing index is returned
+func (d *SQLDialect) CreateIndex(tableName string, columns ...string) error {
+	return d.execCreateIndex("INDEX", "idx_"+tableName+"_"+strings.Join(columns, "_"), tableName, columns)
+}
+
+// CreateUniqueIndex create unique index (named uidx_<table>_<columns>) if not exist, can be used as conflict target of upsert
+func (d *SQLDialect) CreateUniqueIndex(tableName string, columns ...string) error {
+	return d.execCreateIndex("UNIQUE INDEX", "uidx_"+tableName+"_"+strings.Join(columns, "_"), tableName, columns)
+}
+
+func (d *SQLDialect) execCreateIndex(indexType, indexName, tableName string, columns []string) error {
+	if d.IsPostgres {
+		_, err := d.DB.Exec("CREATE " + indexType + " IF NOT EXISTS " + indexName + " ON " + tableName + " (" + strings.Join(columns, ", ") + ")")
+		return err
+	}
+
+	// mysql does not support "IF NOT EXISTS" in create index, existing index is checked first
+	exist, err := d.mysqlIndexExists(tableName, indexName)
+	if err != nil || exist {
+		return err
+	}
+	if _, err := d.DB.Exec("CREATE " + indexType + " " + indexName + " ON " + tableName + " (" + strings.Join(columns, ", ") + ")"); err != nil {
+		// index may created by another instance at the same time
+		if exist, _ := d.mysqlIndexExists(tableName, indexName); !exist {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *SQLDialect) mysqlIndexExists(tableName, indexName string) (bool, error) {
+	var count int
+	query := "SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = ? AND index_name = ?"
+	if err := d.DB.QueryRow(query, tableName, indexName).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed when check index %s of table %s: %v", indexName, tableName, err)
+	}
+	return count > 0, nil
+}
+
+// TimestampType nullable timestamp column type with time zone (postgres) or microsecond precision (mysql)
+func (d *SQLDialect) TimestampType() string {
+	if d.IsPostgres {
+		return "TIMESTAMP WITH TIME ZONE NULL"
+	}
+	return "DATETIME(6) NULL"
+}
+
+// Rebind replace "?" placeholder to "$n" placeholder for postgres
+func (d *SQLDialect) Rebind(query string) string {
+	if !d.IsPostgres {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			b.WriteString(fmt.Sprintf("$%d", n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,61 @@
+package candiutils
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLDialect(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	postgres := NewSQLDialect(db)
+	assert.True(t, postgres.IsPostgres)
+	assert.Equal(t, "SELECT * FROM t WHERE a = $1 AND b IN ($2, $3)", postgres.Rebind("SELECT * FROM t WHERE a = ? AND b IN (?, ?)"))
+	assert.Equal(t, "TIMESTAMP WITH TIME ZONE NULL", postgres.TimestampType())
+
+	columns := []SQLColumn{{Name: "id", DataType: "VARCHAR(255) NOT NULL PRIMARY KEY"}, {Name: "name", DataType: "TEXT"}}
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS t (id VARCHAR(255) NOT NULL PRIMARY KEY, name TEXT)")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE table_name = $1 AND table_schema = current_schema()")).WithArgs("t").
+		WillReturnRows(sqlmock.NewRows([]string{"column_name"}).AddRow("ID"))
+	mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE t ADD COLUMN name TEXT")).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.NoError(t, postgres.CreateTable("t", columns))
+
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS t")).WillReturnError(errors.New("permission denied"))
+	assert.EqualError(t, postgres.CreateTable("t", columns), "failed when create table t: permission denied")
+
+	mock.ExpectExec(regexp.QuoteMeta("CREATE UNIQUE INDEX IF NOT EXISTS uidx_t_id_name ON t (id, name)")).
+		WillReturnError(errors.New("failed"))
+	assert.Error(t, postgres.CreateUniqueIndex("t", "id", "name"))
+
+	mysql := &SQLDialect{DB: db}
+	assert.Equal(t, "SELECT ?", mysql.Rebind("SELECT ?"))
+	assert.Equal(t, "DATETIME(6) NULL", mysql.TimestampType())
+
+	// mysql skip existing index
+	indexQuery := regexp.QuoteMeta("FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = ? AND index_name = ?")
+	mock.ExpectQuery(indexQuery).WithArgs("t", "idx_t_name").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	assert.NoError(t, mysql.CreateIndex("t", "name"))
+
+	// mysql ignore error of index created by another instance at the same time
+	mock.ExpectQuery(indexQuery).WithArgs("t", "idx_t_name").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectExec(regexp.QuoteMeta("CREATE INDEX idx_t_name ON t (name)")).WillReturnError(errors.New("duplicate key name"))
+	mock.ExpectQuery(indexQuery).WithArgs("t", "idx_t_name").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	assert.NoError(t, mysql.CreateIndex("t", "name"))
+
+	// mysql return other error of create index
+	mock.ExpectQuery(indexQuery).WithArgs("t", "uidx_t_id").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectExec(regexp.QuoteMeta("CREATE UNIQUE INDEX uidx_t_id ON t (id)")).WillReturnError(errors.New("command denied"))
+	mock.ExpectQuery(indexQuery).WithArgs("t", "uidx_t_id").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	assert.EqualError(t, mysql.CreateUniqueIndex("t", "id"), "command denied")
+
+	mock.ExpectQuery(indexQuery).WillReturnError(errors.New("connection refused"))
+	assert.Error(t, mysql.CreateIndex("t", "name"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -206,8 +206,8 @@ stageSelectDependencies:
 		fmt.Printf(redFormat, "Redis Subscriber need redis, try again")
 		goto stageSelectDependencies
 	}
-	if workerHandlers[taskqueueHandler] && !(dependencies[redisDeps] && dependencies[mongodbDeps]) {
-		fmt.Printf(redFormat, "Task Queue Worker need redis (for queue) and mongo (for log storage), try again")
+	if workerHandlers[taskqueueHandler] && !(dependencies[redisDeps] && (dependencies[mongodbDeps] || dependencies[sqldbDeps])) {
+		fmt.Printf(redFormat, "Task Queue Worker need redis (for queue) and mongo or sql database (for log storage), try again")
 		goto stageSelectDependencies
 	}
 
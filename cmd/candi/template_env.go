@@ -27,6 +27,10 @@ GRPC_PORT=8002
 TASK_QUEUE_DASHBOARD_PORT=8080
 TASK_QUEUE_DASHBOARD_MAX_CLIENT=5
 
+CRON_WORKER_TIMEZONE= # default timezone of cron worker schedule (example: Asia/Jakarta), empty means local time
+CRON_WORKER_DASHBOARD_PORT= # port of cron worker management REST API, empty means disabled
+CRON_WORKER_HISTORY_STORE= # persist cron worker job run history (mongo, sql, or redis), empty means disabled
+
 GRAPHQL_DISABLE_INTROSPECTION=false
 
 # use consul for distributed lock if run in multiple instance
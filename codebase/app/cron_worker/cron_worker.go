@@ -4,8 +4,10 @@ package cronworker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"reflect"
 	"sync"
@@ -18,20 +20,28 @@ import (
 	"github.com/golangid/candi/config/env"
 	"github.com/golangid/candi/logger"
 	"github.com/golangid/candi/tracer"
+	"github.com/google/uuid"
 )
 
 type cronWorker struct {
 	ctx           context.Context
 	ctxCancelFunc func()
 
-	service factory.ServiceFactory
-	consul  *candiutils.Consul
-	wg      sync.WaitGroup
+	service   factory.ServiceFactory
+	consul    *candiutils.Consul
+	wg        sync.WaitGroup
+	opt       option
+	dashboard *http.Server
+	// stopped closed when shutdown, stop remaining misfire runs
+	stopped chan struct{}
+	// misfireOnce missed activations only executed once when process started, not in each consul rebalance
+	misfireOnce sync.Once
 }
 
 // NewWorker create new cron worker
-func NewWorker(service factory.ServiceFactory) factory.AppServerFactory {
+func NewWorker(service factory.ServiceFactory, opts ...OptionFunc) factory.AppServerFactory {
 	refreshWorkerNotif, shutdown = make(chan struct{}), make(chan struct{})
+	triggerJobCh = make(chan *Job, triggerJobBuffer)
 	semaphore = make(chan struct{}, env.BaseEnv().MaxGoroutines)
 	startWorkerCh, releaseWorkerCh = make(chan struct{}), make(chan struct{})
 
@@ -43,6 +53,10 @@ func NewWorker(service factory.ServiceFactory) factory.AppServerFactory {
 	workers = append(workers, reflect.SelectCase{
 		Dir: reflect.SelectRecv, Chan: reflect.ValueOf(refreshWorkerNotif),
 	})
+	// add trigger job channel to third index
+	workers = append(workers, reflect.SelectCase{
+		Dir: reflect.SelectRecv, Chan: reflect.ValueOf(triggerJobCh),
+	})
 
 	for _, m := range service.GetModules() {
 		if h := m.WorkerHandler(types.Scheduler); h != nil {
@@ -60,7 +74,7 @@ func NewWorker(service factory.ServiceFactory) factory.AppServerFactory {
 					panic(fmt.Errorf(`Cron Worker: "%s" %v`, interval, err))
 				}
 
-				logger.LogYellow(fmt.Sprintf(`[CRON-WORKER] (job name): %s (every): %-8s  --> (module): "%s"`, `"`+funcName+`"`, interval, m.Name()))
+				logger.LogYellow(fmt.Sprintf(`[CRON-WORKER] (job name): %s (schedule): %-8s  --> (module): "%s"`, `"`+funcName+`"`, interval, m.Name()))
 			}
 		}
 	}
@@ -68,7 +82,12 @@ func NewWorker(service factory.ServiceFactory) factory.AppServerFactory {
 
 	c := &cronWorker{
 		service: service,
+		stopped: make(chan struct{}),
 	}
+	for _, opt := range opts {
+		opt(&c.opt)
+	}
+	historyStore = c.opt.HistoryStore
 
 	if env.BaseEnv().UseConsul {
 		consul, err := candiutils.NewConsul(&candiutils.ConsulConfig{
@@ -87,23 +106,50 @@ func NewWorker(service factory.ServiceFactory) factory.AppServerFactory {
 }
 
 func (c *cronWorker) Serve() {
+	if c.opt.DashboardPort > 0 {
+		c.dashboard = &http.Server{
+			Addr:    fmt.Sprintf(":%d", c.opt.DashboardPort),
+			Handler: newDashboardHandler(c.opt.DashboardAuth),
+		}
+		go func() {
+			if err := c.dashboard.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				panic(fmt.Errorf("cron worker dashboard: %v", err))
+			}
+		}()
+	}
+
 	c.createConsulSession()
 
 START:
 	select {
 	case <-startWorkerCh:
+		setActiveScheduler(true)
 		startAllJob()
+		c.misfireOnce.Do(func() {
+			c.wg.Add(1)
+			go func() {
+				defer c.wg.Done()
+				c.runMisfiredJobs()
+			}()
+		})
 		totalRunJobs := 0
+		var cases []reflect.SelectCase
 
 		// run worker
 		for {
-			chosen, _, ok := reflect.Select(workers)
+			// select cases of job is changed when job schedule updated, copy with lock then select on copied cases
+			mutex.Lock()
+			cases = append(cases[:0], workers...)
+			mutex.Unlock()
+
+			chosen, value, ok := reflect.Select(cases)
 			if !ok {
 				continue
 			}
 
 			// if shutdown channel captured, break loop (no more jobs will run)
 			if chosen == 0 {
+				setActiveScheduler(false)
 				return
 			}
 
@@ -112,29 +158,39 @@ START:
 				continue
 			}
 
-			chosen = chosen - 2
-			job := activeJobs[chosen]
-			if job.nextDuration != nil {
-				job.ticker.Stop()
-				job.currentDuration = *job.nextDuration
-				job.ticker = time.NewTicker(*job.nextDuration)
-				workers[job.WorkerIndex].Chan = reflect.ValueOf(job.ticker.C)
-				activeJobs[chosen].nextDuration = nil
+			var job *Job
+			scheduledAt, trigger := time.Now(), TriggerManual
+			if chosen == 2 {
+				// job triggered manually
+				job = value.Interface().(*Job)
+			} else {
+				mutex.Lock()
+				job = activeJobs[chosen-3]
+				scheduledAt, trigger = job.nextRunAt, TriggerSchedule
+				job.resetTimer(time.Now())
+				mutex.Unlock()
+			}
+
+			if ok, skipReason := job.tryStart(scheduledAt, trigger); !ok {
+				if skipReason != "" {
+					c.saveSkippedRun(job, scheduledAt, trigger, skipReason)
+				}
+				if env.BaseEnv().DebugMode && skipReason == SkipReasonOverlap {
+					log.Printf("\x1b[33;3mCron Scheduler: task '%s' is still running (overlap policy: %s)\x1b[0m",
+						job.HandlerName, job.Handler.OverlapPolicy)
+				}
+				continue
 			}
 
 			semaphore <- struct{}{}
 			c.wg.Add(1)
 			go func(j *Job) {
 				defer func() {
-					c.wg.Done()
 					<-semaphore
+					c.wg.Done()
 				}()
 
-				if c.ctx.Err() != nil {
-					logger.LogRed("cron_scheduler > ctx root err: " + c.ctx.Err().Error())
-					return
-				}
-				c.processJob(j)
+				c.execJob(j, scheduledAt, trigger)
 			}(job)
 
 			if c.consul != nil {
@@ -142,6 +198,7 @@ START:
 				// if already running n jobs, release lock so that run in another instance
 				if totalRunJobs == env.BaseEnv().ConsulMaxJobRebalance {
 					// recreate session
+					setActiveScheduler(false)
 					c.createConsulSession()
 					<-releaseWorkerCh
 					goto START
@@ -164,6 +221,11 @@ func (c *cronWorker) Shutdown(ctx context.Context) {
 		log.Println("\x1b[33;1mStopping Cron Job Scheduler:\x1b[0m \x1b[32;1mSUCCESS\x1b[0m")
 	}()
 
+	if c.dashboard != nil {
+		c.dashboard.Shutdown(ctx)
+	}
+	close(c.stopped)
+
 	if len(activeJobs) == 0 {
 		return
 	}
@@ -196,32 +258,137 @@ func (c *cronWorker) createConsulSession() {
 	go c.consul.RetryLockAcquire(value, startWorkerCh, releaseWorkerCh)
 }
 
-func (c *cronWorker) processJob(job *Job) {
+// runMisfiredJobs execute missed activations of all jobs based on last run in history store and job misfire policy,
+// activation of paused job or still running job (overlap policy) is skipped
+func (c *cronWorker) runMisfiredJobs() {
+	if historyStore == nil {
+		return
+	}
+
+	now := time.Now()
+	for _, job := range activeJobs {
+		activations, err := job.loadLastRun(c.ctx, now)
+		if err != nil {
+			logger.LogE(fmt.Sprintf("cron_scheduler > load last run of job %s: %v", job.HandlerName, err))
+			continue
+		}
+		if len(activations) == 0 {
+			continue
+		}
+
+		logger.LogYellow(fmt.Sprintf("cron_scheduler > job %s missed %d activation, misfire policy: %s",
+			job.HandlerName, len(activations), job.Handler.MisfirePolicy))
+		c.wg.Add(1)
+		go func(job *Job, activations []time.Time) {
+			defer c.wg.Done()
+
+			// missed activations of same job executed sequentially
+			for _, scheduledAt := range activations {
+				select {
+				case <-c.stopped:
+					return
+				case semaphore <- struct{}{}:
+				}
+				if ok, skipReason := job.tryStart(scheduledAt, TriggerMisfire); ok {
+					c.execJob(job, scheduledAt, TriggerMisfire)
+				} else if skipReason != "" {
+					c.saveSkippedRun(job, scheduledAt, TriggerMisfire, skipReason)
+				}
+				<-semaphore
+			}
+		}(job, activations)
+	}
+}
+
+// execJob execute job run, then execute queued run of job (overlap policy queue) in the same goroutine,
+// must called after job.tryStart return true
+func (c *cronWorker) execJob(job *Job, scheduledAt time.Time, trigger string) {
+	for {
+		if c.ctx.Err() != nil {
+			logger.LogRed("cron_scheduler > ctx root err: " + c.ctx.Err().Error())
+			job.releaseRun(true)
+			return
+		}
+
+		c.processJob(job, scheduledAt, trigger)
+		next := job.releaseRun(false)
+		if next == nil {
+			return
+		}
+		scheduledAt, trigger = next.scheduledAt, next.trigger
+	}
+}
+
+func (c *cronWorker) processJob(job *Job, scheduledAt time.Time, trigger string) {
 	ctx := c.ctx
 	if job.Handler.DisableTrace {
 		ctx = tracer.SkipTraceContext(ctx)
 	}
 
 	trace, ctx := tracer.StartTraceWithContext(ctx, "CronScheduler")
+	startTime := job.markStarted()
+	run := &JobRun{
+		ID: uuid.NewString(), JobName: job.HandlerName, Trigger: trigger,
+		ScheduledAt: scheduledAt, StartedAt: startTime, TraceID: tracer.GetTraceID(ctx),
+	}
+	c.saveRun(run)
+
+	var err error
 	defer func() {
 		if r := recover(); r != nil {
-			trace.SetError(fmt.Errorf("%v", r))
+			err = fmt.Errorf("%v", r)
+			trace.SetError(err)
+		}
+		job.markFinished(startTime, err)
+		run.Duration, run.IsFinished = time.Since(startTime), true
+		if err != nil {
+			run.Error = err.Error()
 		}
+		c.saveRun(run)
 		logger.LogGreen("cron scheduler > trace_url: " + tracer.GetTraceURL(ctx))
 		trace.Finish()
 	}()
 	trace.SetTag("job_name", job.HandlerName)
 	trace.SetTag("job_param", job.Params)
+	trace.SetTag("trigger", trigger)
 
 	if env.BaseEnv().DebugMode {
 		log.Printf("\x1b[35;3mCron Scheduler: executing task '%s' (interval: %s)\x1b[0m", job.HandlerName, job.Interval)
 	}
 
+	if job.Handler.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, job.Handler.Timeout)
+		defer cancel()
+	}
+
 	params := []byte(job.Params)
-	if err := job.Handler.HandlerFunc(ctx, params); err != nil {
+	err = job.Handler.HandlerFunc(ctx, params)
+	if err == nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("job exceeded max execution timeout %s", job.Handler.Timeout)
+	}
+	if err != nil {
 		if job.Handler.ErrorHandler != nil {
 			job.Handler.ErrorHandler(ctx, types.RabbitMQ, job.HandlerName, params, err)
 		}
 		trace.SetError(err)
 	}
 }
+
+// saveSkippedRun save skipped activation to history store, so skipped activation is not evaluated as misfire on next startup
+func (c *cronWorker) saveSkippedRun(job *Job, scheduledAt time.Time, trigger, skipReason string) {
+	c.saveRun(&JobRun{
+		ID: uuid.NewString(), JobName: job.HandlerName, Trigger: trigger,
+		ScheduledAt: scheduledAt, IsFinished: true, SkipReason: skipReason,
+	})
+}
+
+// saveRun save job run to history store, history is saved even if worker context has been canceled
+func (c *cronWorker) saveRun(run *JobRun) {
+	if historyStore == nil {
+		return
+	}
+	if err := historyStore.SaveRun(context.Background(), run); err != nil {
+		logger.LogE(fmt.Sprintf("cron_scheduler > save run of job %s: %v", run.JobName, err))
+	}
+}
@@ -0,0 +1,156 @@
+package cronworker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/golangid/candi/middleware"
+	"github.com/golangid/candi/wrapper"
+)
+
+const (
+	dashboardJobsPath = "/cron/jobs"
+
+	defaultHistoryLimit = 20
+	maxHistoryLimit     = 100
+)
+
+// dashboardMiddleware wrap handler with authentication and authorization, operator is true for mutation handler
+// (trigger, pause, resume, and change schedule of job), API not protected if auth is not set
+func dashboardMiddleware(auth *middleware.DashboardAuth, next http.Handler, operator bool) http.Handler {
+	return auth.HTTPMiddleware(next, auth.PermissionCode(operator))
+}
+
+/*
+newDashboardHandler management REST API:
+
+	GET  /cron/jobs                 list all jobs
+	GET  /cron/jobs/{name}          get job
+	GET  /cron/jobs/{name}/history  get latest runs of job from history store (query param: limit, default 20)
+	POST /cron/jobs/{name}/trigger  execute job immediately
+	POST /cron/jobs/{name}/pause    pause job
+	POST /cron/jobs/{name}/resume   resume paused job
+	PUT  /cron/jobs/{name}/schedule change job schedule with body {"interval": "0 2 * * *", "timezone": "Asia/Jakarta"}
+*/
+func newDashboardHandler(auth *middleware.DashboardAuth) http.Handler {
+	routes := map[string]http.Handler{
+		http.MethodGet + " ":         dashboardMiddleware(auth, http.HandlerFunc(getJobHandler), false),
+		http.MethodGet + " history":  dashboardMiddleware(auth, http.HandlerFunc(getJobHistoryHandler), false),
+		http.MethodPost + " trigger": dashboardMiddleware(auth, jobActionHandler(TriggerJob, "Job has been triggered"), true),
+		http.MethodPost + " pause":   dashboardMiddleware(auth, jobActionHandler(PauseJob, "Job has been paused"), true),
+		http.MethodPost + " resume":  dashboardMiddleware(auth, jobActionHandler(ResumeJob, "Job has been resumed"), true),
+		http.MethodPut + " schedule": dashboardMiddleware(auth, http.HandlerFunc(updateJobScheduleHandler), true),
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(dashboardJobsPath, dashboardMiddleware(auth, http.HandlerFunc(listJobsHandler), false))
+	mux.HandleFunc(dashboardJobsPath+"/", func(w http.ResponseWriter, req *http.Request) {
+		_, action := parseJobPath(req)
+		handler, ok := routes[req.Method+" "+action]
+		if !ok {
+			wrapper.NewHTTPResponse(http.StatusNotFound, fmt.Sprintf("Route %s %s not found", req.Method, req.URL.Path)).JSON(w)
+			return
+		}
+		handler.ServeHTTP(w, req)
+	})
+	return mux
+}
+
+// parseJobPath get job name and action from request path /cron/jobs/{name}/{action}
+func parseJobPath(req *http.Request) (jobName, action string) {
+	parts := strings.SplitN(strings.Trim(strings.TrimPrefix(req.URL.Path, dashboardJobsPath+"/"), "/"), "/", 2)
+	jobName = parts[0]
+	if len(parts) > 1 {
+		action = parts[1]
+	}
+	return
+}
+
+func listJobsHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		wrapper.NewHTTPResponse(http.StatusMethodNotAllowed, "Method not allowed").JSON(w)
+		return
+	}
+	wrapper.NewHTTPResponse(http.StatusOK, "Success", GetJobDetails()).JSON(w)
+}
+
+func getJobHandler(w http.ResponseWriter, req *http.Request) {
+	jobName, _ := parseJobPath(req)
+	detail, err := GetJobDetail(jobName)
+	if err != nil {
+		jobErrorResponse(w, err)
+		return
+	}
+	wrapper.NewHTTPResponse(http.StatusOK, "Success", detail).JSON(w)
+}
+
+func getJobHistoryHandler(w http.ResponseWriter, req *http.Request) {
+	limit, err := strconv.Atoi(req.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	if limit > maxHistoryLimit {
+		limit = maxHistoryLimit
+	}
+
+	jobName, _ := parseJobPath(req)
+	runs, err := GetJobHistory(req.Context(), jobName, limit)
+	if err != nil {
+		jobErrorResponse(w, err)
+		return
+	}
+	if runs == nil {
+		runs = []JobRun{}
+	}
+	wrapper.NewHTTPResponse(http.StatusOK, "Success", runs).JSON(w)
+}
+
+func jobActionHandler(action func(jobName string) error, message string) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		jobName, _ := parseJobPath(req)
+		if err := action(jobName); err != nil {
+			jobErrorResponse(w, err)
+			return
+		}
+		detail, _ := GetJobDetail(jobName)
+		wrapper.NewHTTPResponse(http.StatusOK, message, detail).JSON(w)
+	}
+}
+
+func updateJobScheduleHandler(w http.ResponseWriter, req *http.Request) {
+	var payload struct {
+		Interval string `json:"interval"`
+		Timezone string `json:"timezone"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+		wrapper.NewHTTPResponse(http.StatusBadRequest, "Invalid request body", err).JSON(w)
+		return
+	}
+	if payload.Interval == "" {
+		wrapper.NewHTTPResponse(http.StatusBadRequest, "Interval cannot empty").JSON(w)
+		return
+	}
+
+	jobName, _ := parseJobPath(req)
+	if err := UpdateJobSchedule(jobName, payload.Interval, payload.Timezone); err != nil {
+		jobErrorResponse(w, err)
+		return
+	}
+	detail, _ := GetJobDetail(jobName)
+	wrapper.NewHTTPResponse(http.StatusOK, "Job schedule has been updated", detail).JSON(w)
+}
+
+func jobErrorResponse(w http.ResponseWriter, err error) {
+	code := http.StatusBadRequest
+	switch {
+	case errors.Is(err, ErrJobNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, ErrNotActiveScheduler):
+		code = http.StatusConflict
+	}
+	wrapper.NewHTTPResponse(code, err.Error()).JSON(w)
+}
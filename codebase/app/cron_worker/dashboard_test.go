@@ -0,0 +1,125 @@
+package cronworker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golangid/candi/middleware"
+	mockinterfaces "github.com/golangid/candi/mocks/codebase/interfaces"
+	"github.com/golangid/candi/wrapper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDashboardAPI(t *testing.T) {
+	refreshWorkerNotif, triggerJobCh = make(chan struct{}, 10), make(chan *Job, triggerJobBuffer)
+	defer func() {
+		for _, job := range activeJobs {
+			job.timer.Stop()
+		}
+		activeJobs, workers, refreshWorkerNotif, triggerJobCh = nil, nil, nil, nil
+	}()
+
+	var job Job
+	job.HandlerName, job.Interval, job.Timezone = "daily-report", "0 2 * * *", "UTC"
+	job.Handler.HandlerFunc = func(ctx context.Context, message []byte) error { return nil }
+	assert.NoError(t, AddJob(job))
+	assert.Error(t, AddJob(job))
+
+	handler := newDashboardHandler(nil)
+	request := func(method, path, body string) (resp wrapper.HTTPResponse, detail JobDetail) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		resp.Data = &detail
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+		assert.Equal(t, resp.Code, rec.Code)
+		return resp, detail
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/cron/jobs", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"job_name":"daily-report"`)
+
+	resp, detail := request(http.MethodGet, "/cron/jobs/daily-report", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "UTC", detail.Timezone)
+	assert.Equal(t, 2, detail.NextRunAt.Hour())
+	assert.Nil(t, detail.LastRunAt)
+
+	resp, _ = request(http.MethodGet, "/cron/jobs/unknown", "")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	resp, _ = request(http.MethodDelete, "/cron/jobs/daily-report", "")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	_, detail = request(http.MethodPost, "/cron/jobs/daily-report/pause", "")
+	assert.True(t, detail.IsPaused)
+	_, detail = request(http.MethodPost, "/cron/jobs/daily-report/resume", "")
+	assert.False(t, detail.IsPaused)
+
+	resp, _ = request(http.MethodPost, "/cron/jobs/daily-report/trigger", "")
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	setActiveScheduler(true)
+	defer setActiveScheduler(false)
+	resp, _ = request(http.MethodPost, "/cron/jobs/daily-report/trigger", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "daily-report", (<-triggerJobCh).HandlerName)
+
+	resp, _ = request(http.MethodPut, "/cron/jobs/daily-report/schedule", `{"interval": "0 0 L * *"}`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	<-refreshWorkerNotif
+	resp, _ = request(http.MethodPut, "/cron/jobs/daily-report/schedule", `{"interval": "0 9 * * *", "timezone": "Asia/Jakarta"}`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	<-refreshWorkerNotif
+	_, detail = request(http.MethodGet, "/cron/jobs/daily-report", "")
+	assert.Equal(t, "0 9 * * *", detail.Interval)
+	assert.Equal(t, "Asia/Jakarta", detail.Timezone)
+	assert.Equal(t, 9, detail.NextRunAt.Hour())
+
+	resp, _ = request(http.MethodPut, "/cron/jobs/daily-report/schedule", `{"interval": "0 25 * * *"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	resp, _ = request(http.MethodPut, "/cron/jobs/daily-report/schedule", `{}`)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	startTime := activeJobs[0].markStarted()
+	activeJobs[0].markFinished(startTime, assert.AnError)
+	_, detail = request(http.MethodGet, "/cron/jobs/daily-report", "")
+	assert.Equal(t, 1, detail.TotalRun)
+	assert.NotNil(t, detail.LastRunAt)
+	assert.Equal(t, assert.AnError.Error(), detail.LastError)
+}
+
+func TestDashboardAuth(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) { w.WriteHeader(http.StatusOK) })
+	var nilAuth *middleware.DashboardAuth
+	assert.NotNil(t, dashboardMiddleware(nilAuth, next, true))
+
+	var aclCodes []string
+	mw := &mockinterfaces.Middleware{}
+	mw.On("HTTPBasicAuth", mock.Anything).Return(next)
+	mw.On("HTTPBearerAuth", mock.Anything).Return(func(next http.Handler) http.Handler { return next })
+	mw.On("HTTPPermissionACL", mock.Anything).Return(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) { w.WriteHeader(http.StatusForbidden) })
+	}).Run(func(args mock.Arguments) {
+		aclCodes = append(aclCodes, args.String(0))
+	})
+
+	auth := &middleware.DashboardAuth{Middleware: mw, AuthType: middleware.Basic, OperatorPermissionCode: "operator"}
+	dashboardMiddleware(auth, next, true)
+	mw.AssertCalled(t, "HTTPBasicAuth", mock.Anything)
+
+	auth = &middleware.DashboardAuth{Middleware: mw, AuthType: middleware.Bearer, OperatorPermissionCode: "operator"}
+	dashboardMiddleware(auth, next, false)
+	assert.Empty(t, aclCodes)
+
+	rec := httptest.NewRecorder()
+	dashboardMiddleware(auth, next, true).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/cron/jobs/job/trigger", nil))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, []string{"operator"}, aclCodes)
+
+	assert.Panics(t, func() { SetDashboardAuth(middleware.DashboardAuth{AuthType: middleware.Bearer}) })
+}
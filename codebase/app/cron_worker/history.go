@@ -0,0 +1,115 @@
+package cronworker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// MisfirePolicySkip missed activation when service is down is not executed (default)
+	MisfirePolicySkip = "skip"
+	// MisfirePolicyRunOnce job is executed once on startup if there are missed activations
+	MisfirePolicyRunOnce = "run_once"
+	// MisfirePolicyRunAll job is executed for each missed activation on startup (max 100 runs)
+	MisfirePolicyRunAll = "run_all"
+
+	// TriggerSchedule job run activated by schedule
+	TriggerSchedule = "schedule"
+	// TriggerManual job run triggered manually from management API
+	TriggerManual = "manual"
+	// TriggerMisfire job run of missed activation
+	TriggerMisfire = "misfire"
+
+	// SkipReasonPaused activation skipped because job is paused
+	SkipReasonPaused = "paused"
+	// SkipReasonOverlap activation skipped because previous run is still running (overlap policy skip or queue is full)
+	SkipReasonOverlap = "overlap"
+
+	maxMisfireRuns = 100
+)
+
+// JobRun history of job execution
+type JobRun struct {
+	ID          string        `json:"id" bson:"_id"`
+	JobName     string        `json:"job_name" bson:"job_name"`
+	Trigger     string        `json:"trigger" bson:"trigger"`
+	ScheduledAt time.Time     `json:"scheduled_at" bson:"scheduled_at"`
+	StartedAt   time.Time     `json:"started_at" bson:"started_at"`
+	Duration    time.Duration `json:"duration" bson:"duration"`
+	IsFinished  bool          `json:"is_finished" bson:"is_finished"`
+	Error       string        `json:"error,omitempty" bson:"error,omitempty"`
+	TraceID     string        `json:"trace_id,omitempty" bson:"trace_id,omitempty"`
+	// SkipReason not empty if activation is not executed, skipped activation is saved so it is not evaluated as misfire
+	SkipReason string `json:"skip_reason,omitempty" bson:"skip_reason,omitempty"`
+}
+
+// HistoryStore abstraction for persist history of job execution, also used for evaluate misfire policy
+type HistoryStore interface {
+	// SaveRun insert or update job run by ID, job run is saved when started and when finished
+	SaveRun(ctx context.Context, run *JobRun) error
+	// FindLastRun find job run with latest scheduled time, return nil without error if job never executed
+	FindLastRun(ctx context.Context, jobName string) (*JobRun, error)
+	// FindRuns find job runs sorted by latest scheduled time
+	FindRuns(ctx context.Context, jobName string, limit int) ([]JobRun, error)
+}
+
+var historyStore HistoryStore
+
+// GetJobHistory get latest job runs of job with given name from history store
+func GetJobHistory(ctx context.Context, jobName string, limit int) ([]JobRun, error) {
+	mutex.Lock()
+	_, err := findJob(jobName)
+	mutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if historyStore == nil {
+		return nil, errors.New("history store is not configured")
+	}
+	return historyStore.FindRuns(ctx, jobName, limit)
+}
+
+func validateMisfirePolicy(policy string) error {
+	switch policy {
+	case "", MisfirePolicySkip, MisfirePolicyRunOnce, MisfirePolicyRunAll:
+		return nil
+	}
+	return fmt.Errorf(`invalid misfire policy "%s" (must one of "%s", "%s", "%s")`,
+		policy, MisfirePolicySkip, MisfirePolicyRunOnce, MisfirePolicyRunAll)
+}
+
+// missedActivations get activation times of job after last run until given time, based on job misfire policy
+func (j *Job) missedActivations(lastRun *JobRun, now time.Time) (activations []time.Time) {
+	if lastRun == nil || j.Handler.MisfirePolicy == "" || j.Handler.MisfirePolicy == MisfirePolicySkip {
+		return nil
+	}
+
+	for t := j.schedule.Next(lastRun.ScheduledAt.In(j.location)); !t.IsZero() && !t.After(now); t = j.schedule.Next(t) {
+		activations = append(activations, t)
+		if len(activations) > maxMisfireRuns {
+			activations = activations[1:]
+		}
+	}
+	if j.Handler.MisfirePolicy == MisfirePolicyRunOnce && len(activations) > 0 {
+		activations = activations[len(activations)-1:]
+	}
+	return activations
+}
+
+// loadLastRun load last run of job from history store to job state, return missed activations of job
+func (j *Job) loadLastRun(ctx context.Context, now time.Time) ([]time.Time, error) {
+	lastRun, err := historyStore.FindLastRun(ctx, j.HandlerName)
+	if err != nil || lastRun == nil {
+		return nil, err
+	}
+
+	mutex.Lock()
+	if lastRun.StartedAt.After(j.lastRunAt) {
+		j.lastRunAt, j.lastDuration, j.lastError = lastRun.StartedAt, lastRun.Duration, lastRun.Error
+	}
+	activations := j.missedActivations(lastRun, now)
+	mutex.Unlock()
+	return activations, nil
+}
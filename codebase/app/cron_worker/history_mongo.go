@@ -0,0 +1,73 @@
+package cronworker
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const mongoRunColl = "cron_worker_job_runs"
+
+type mongoHistoryStore struct {
+	db *mongo.Database
+}
+
+// NewMongoHistoryStore create mongodb history store
+func NewMongoHistoryStore(db *mongo.Database) HistoryStore {
+	db.Collection(mongoRunColl).Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "job_name", Value: 1},
+			{Key: "scheduled_at", Value: -1},
+		},
+	})
+	return &mongoHistoryStore{db}
+}
+
+func (s *mongoHistoryStore) SaveRun(ctx context.Context, run *JobRun) error {
+	_, err := s.db.Collection(mongoRunColl).UpdateOne(ctx,
+		bson.M{
+			"_id": run.ID,
+		},
+		bson.M{
+			"$set": run,
+		}, options.Update().SetUpsert(true))
+	return err
+}
+
+func (s *mongoHistoryStore) FindLastRun(ctx context.Context, jobName string) (*JobRun, error) {
+	var run JobRun
+	err := s.db.Collection(mongoRunColl).FindOne(ctx, bson.M{"job_name": jobName},
+		&options.FindOneOptions{Sort: bson.M{"scheduled_at": -1}}).Decode(&run)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func (s *mongoHistoryStore) FindRuns(ctx context.Context, jobName string, limit int) (runs []JobRun, err error) {
+	findOptions := &options.FindOptions{
+		Sort: bson.M{"scheduled_at": -1},
+	}
+	findOptions.SetLimit(int64(limit))
+
+	cur, err := s.db.Collection(mongoRunColl).Find(ctx, bson.M{"job_name": jobName}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var run JobRun
+		if err := cur.Decode(&run); err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, cur.Err()
+}
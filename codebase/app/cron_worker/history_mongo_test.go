@@ -0,0 +1,59 @@
+package cronworker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestMongoHistoryStore(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("SaveRun", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+		store := NewMongoHistoryStore(mt.DB)
+
+		run := &JobRun{ID: "run-1", JobName: "job", Trigger: TriggerSchedule, ScheduledAt: time.Now()}
+		assert.NoError(mt, store.SaveRun(context.Background(), run))
+
+		// upsert by run ID
+		started := mt.GetStartedEvent()
+		assert.Equal(mt, "createIndexes", started.CommandName)
+		started = mt.GetStartedEvent()
+		assert.Equal(mt, "update", started.CommandName)
+		update := started.Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(mt, "run-1", update.Lookup("q", "_id").StringValue())
+		assert.True(mt, update.Lookup("upsert").Boolean())
+	})
+
+	mt.Run("FindRuns", func(mt *mtest.T) {
+		scheduledAt := time.Date(2021, time.January, 31, 10, 0, 0, 0, time.UTC)
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateCursorResponse(0, "db."+mongoRunColl, mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: "run-2"}, {Key: "job_name", Value: "job"}, {Key: "scheduled_at", Value: scheduledAt.Add(time.Hour)}},
+			bson.D{{Key: "_id", Value: "run-1"}, {Key: "job_name", Value: "job"}, {Key: "scheduled_at", Value: scheduledAt}},
+		))
+		store := NewMongoHistoryStore(mt.DB)
+
+		runs, err := store.FindRuns(context.Background(), "job", 2)
+		assert.NoError(mt, err)
+		assert.Len(mt, runs, 2)
+		assert.Equal(mt, "run-2", runs[0].ID)
+
+		// sorted by latest scheduled time
+		mt.GetStartedEvent()
+		find := mt.GetStartedEvent().Command
+		assert.Equal(mt, "job", find.Lookup("filter", "job_name").StringValue())
+		assert.Equal(mt, int32(-1), find.Lookup("sort", "scheduled_at").Int32())
+		assert.Equal(mt, int64(2), find.Lookup("limit").Int64())
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "db."+mongoRunColl, mtest.FirstBatch))
+		lastRun, err := store.FindLastRun(context.Background(), "job")
+		assert.NoError(mt, err)
+		assert.Nil(mt, lastRun)
+	})
+}
@@ -0,0 +1,86 @@
+package cronworker
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// maxRedisJobRuns max latest runs of each job kept in redis history store
+const maxRedisJobRuns = 1000
+
+type redisHistoryStore struct {
+	pool *redis.Pool
+}
+
+// NewRedisHistoryStore create redis history store, only keep latest 1000 runs of each job
+func NewRedisHistoryStore(pool *redis.Pool) HistoryStore {
+	return &redisHistoryStore{pool: pool}
+}
+
+// keys sorted set of run ID (scored by scheduled time) and hash of encoded run for given job
+func (r *redisHistoryStore) keys(jobName string) (runIDs, runs string) {
+	return "cron_worker:runs:" + jobName, "cron_worker:runs_data:" + jobName
+}
+
+func (r *redisHistoryStore) SaveRun(ctx context.Context, run *JobRun) error {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+
+	runIDsKey, runsKey := r.keys(run.JobName)
+	conn.Send("MULTI")
+	conn.Send("ZADD", runIDsKey, run.ScheduledAt.UnixNano(), run.ID)
+	conn.Send("HSET", runsKey, run.ID, data)
+	if _, err := conn.Do("EXEC"); err != nil {
+		return err
+	}
+
+	// remove oldest runs
+	removed, err := redis.Strings(conn.Do("ZRANGE", runIDsKey, 0, -maxRedisJobRuns-1))
+	if err != nil || len(removed) == 0 {
+		return err
+	}
+	conn.Send("MULTI")
+	conn.Send("ZREM", redis.Args{}.Add(runIDsKey).AddFlat(removed)...)
+	conn.Send("HDEL", redis.Args{}.Add(runsKey).AddFlat(removed)...)
+	_, err = conn.Do("EXEC")
+	return err
+}
+
+func (r *redisHistoryStore) FindLastRun(ctx context.Context, jobName string) (*JobRun, error) {
+	runs, err := r.FindRuns(ctx, jobName, 1)
+	if err != nil || len(runs) == 0 {
+		return nil, err
+	}
+	return &runs[0], nil
+}
+
+func (r *redisHistoryStore) FindRuns(ctx context.Context, jobName string, limit int) (runs []JobRun, err error) {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	runIDsKey, runsKey := r.keys(jobName)
+	ids, err := redis.Strings(conn.Do("ZREVRANGE", runIDsKey, 0, limit-1))
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	values, err := redis.ByteSlices(conn.Do("HMGET", redis.Args{}.Add(runsKey).AddFlat(ids)...))
+	if err != nil {
+		return nil, err
+	}
+	for _, value := range values {
+		var run JobRun
+		if value == nil || json.Unmarshal(value, &run) != nil {
+			continue
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
@@ -0,0 +1,56 @@
+package cronworker
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisHistoryStore(t *testing.T) {
+	ctx := context.Background()
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+
+	store := NewRedisHistoryStore(&redis.Pool{Dial: func() (redis.Conn, error) { return redis.Dial("tcp", mr.Addr()) }})
+	lastRun, err := store.FindLastRun(ctx, "job")
+	assert.NoError(t, err)
+	assert.Nil(t, lastRun)
+
+	// saved when started and updated when finished
+	scheduledAt := time.Date(2021, time.January, 31, 10, 0, 0, 0, time.UTC)
+	run := &JobRun{ID: "run-1", JobName: "job", Trigger: TriggerSchedule, ScheduledAt: scheduledAt, StartedAt: scheduledAt}
+	assert.NoError(t, store.SaveRun(ctx, run))
+	run.Duration, run.IsFinished, run.Error = time.Second, true, "failed"
+	assert.NoError(t, store.SaveRun(ctx, run))
+	assert.NoError(t, store.SaveRun(ctx, &JobRun{ID: "run-0", JobName: "job", ScheduledAt: scheduledAt.Add(-time.Hour)}))
+	assert.NoError(t, store.SaveRun(ctx, &JobRun{ID: "run-2", JobName: "job", ScheduledAt: scheduledAt.Add(time.Hour), SkipReason: SkipReasonOverlap}))
+	assert.NoError(t, store.SaveRun(ctx, &JobRun{ID: "other", JobName: "other-job", ScheduledAt: scheduledAt.Add(2 * time.Hour)}))
+
+	runs, err := store.FindRuns(ctx, "job", 10)
+	assert.NoError(t, err)
+	assert.Len(t, runs, 3)
+	assert.Equal(t, []string{"run-2", "run-1", "run-0"}, []string{runs[0].ID, runs[1].ID, runs[2].ID})
+	assert.Equal(t, SkipReasonOverlap, runs[0].SkipReason)
+	assert.True(t, runs[1].IsFinished)
+	assert.Equal(t, "failed", runs[1].Error)
+
+	lastRun, err = store.FindLastRun(ctx, "job")
+	assert.NoError(t, err)
+	assert.Equal(t, "run-2", lastRun.ID)
+
+	// only latest runs are kept
+	for i := 0; i < maxRedisJobRuns; i++ {
+		assert.NoError(t, store.SaveRun(ctx, &JobRun{ID: fmt.Sprintf("new-%d", i), JobName: "job", ScheduledAt: scheduledAt.Add(time.Duration(i+2) * time.Hour)}))
+	}
+	runs, err = store.FindRuns(ctx, "job", maxRedisJobRuns+10)
+	assert.NoError(t, err)
+	assert.Len(t, runs, maxRedisJobRuns)
+	assert.Equal(t, fmt.Sprintf("new-%d", maxRedisJobRuns-1), runs[0].ID)
+	assert.Equal(t, "new-0", runs[len(runs)-1].ID)
+}
@@ -0,0 +1,95 @@
+package cronworker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/golangid/candi/candiutils"
+	"github.com/golangid/candi/logger"
+)
+
+const sqlRunTable = "cron_worker_job_runs"
+
+type sqlHistoryStore struct {
+	db *sql.DB
+	*candiutils.SQLDialect
+}
+
+// NewSQLHistoryStore create sql database history store, support postgres and mysql (mysql DSN must contains "parseTime=true")
+func NewSQLHistoryStore(db *sql.DB) HistoryStore {
+	s := &sqlHistoryStore{db: db, SQLDialect: candiutils.NewSQLDialect(db)}
+
+	if err := s.CreateTable(sqlRunTable, []candiutils.SQLColumn{
+		{Name: "id", DataType: "VARCHAR(255) PRIMARY KEY NOT NULL"},
+		{Name: "job_name", DataType: "VARCHAR(255) NOT NULL"},
+		{Name: "trigger_type", DataType: "VARCHAR(50) NOT NULL"},
+		{Name: "scheduled_at", DataType: s.TimestampType()},
+		{Name: "started_at", DataType: s.TimestampType()},
+		{Name: "duration", DataType: "BIGINT NOT NULL DEFAULT 0"},
+		{Name: "is_finished", DataType: "BOOLEAN NOT NULL DEFAULT false"},
+		{Name: "error_message", DataType: "TEXT"},
+		{Name: "trace_id", DataType: "VARCHAR(255)"},
+		{Name: "skip_reason", DataType: "VARCHAR(50)"},
+	}); err != nil {
+		panic(fmt.Errorf("cron worker: %v", err))
+	}
+	if err := s.CreateIndex(sqlRunTable, "job_name", "scheduled_at"); err != nil {
+		logger.LogE(err.Error())
+	}
+	return s
+}
+
+func (s *sqlHistoryStore) SaveRun(ctx context.Context, run *JobRun) error {
+	query := "INSERT INTO " + sqlRunTable +
+		" (id, job_name, trigger_type, scheduled_at, started_at, duration, is_finished, error_message, trace_id, skip_reason)" +
+		" VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	if s.IsPostgres {
+		query += " ON CONFLICT (id) DO UPDATE SET duration = EXCLUDED.duration, is_finished = EXCLUDED.is_finished," +
+			" error_message = EXCLUDED.error_message"
+	} else {
+		query += " ON DUPLICATE KEY UPDATE duration = VALUES(duration), is_finished = VALUES(is_finished)," +
+			" error_message = VALUES(error_message)"
+	}
+
+	_, err := s.db.ExecContext(ctx, s.Rebind(query), run.ID, run.JobName, run.Trigger, run.ScheduledAt, run.StartedAt,
+		int64(run.Duration), run.IsFinished, run.Error, run.TraceID, run.SkipReason)
+	return err
+}
+
+func (s *sqlHistoryStore) FindLastRun(ctx context.Context, jobName string) (*JobRun, error) {
+	runs, err := s.FindRuns(ctx, jobName, 1)
+	if err != nil || len(runs) == 0 {
+		return nil, err
+	}
+	return &runs[0], nil
+}
+
+func (s *sqlHistoryStore) FindRuns(ctx context.Context, jobName string, limit int) (runs []JobRun, err error) {
+	query := "SELECT id, job_name, trigger_type, scheduled_at, started_at, duration, is_finished, error_message, trace_id, skip_reason FROM " +
+		sqlRunTable + " WHERE job_name = ? ORDER BY scheduled_at DESC LIMIT ?"
+	rows, err := s.db.QueryContext(ctx, s.Rebind(query), jobName, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			run                    JobRun
+			scheduledAt, startedAt sql.NullTime
+			duration               int64
+			errMessage, traceID    sql.NullString
+			skipReason             sql.NullString
+		)
+		if err := rows.Scan(&run.ID, &run.JobName, &run.Trigger, &scheduledAt, &startedAt, &duration, &run.IsFinished,
+			&errMessage, &traceID, &skipReason); err != nil {
+			return nil, err
+		}
+		run.ScheduledAt, run.StartedAt, run.Duration = scheduledAt.Time, startedAt.Time, time.Duration(duration)
+		run.Error, run.TraceID, run.SkipReason = errMessage.String, traceID.String, skipReason.String
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
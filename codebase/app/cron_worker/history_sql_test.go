@@ -0,0 +1,74 @@
+package cronworker
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golangid/candi/candiutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLHistoryStore(t *testing.T) {
+	ctx := context.Background()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	// sqlmock driver detected as postgres, missing column in existing table is added
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS " + sqlRunTable)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT column_name FROM information_schema.columns").WithArgs(sqlRunTable).
+		WillReturnRows(sqlmock.NewRows([]string{"column_name"}).AddRow("id").AddRow("job_name").AddRow("trigger_type").
+			AddRow("scheduled_at").AddRow("started_at").AddRow("duration").AddRow("is_finished").AddRow("error_message").
+			AddRow("trace_id"))
+	mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE " + sqlRunTable + " ADD COLUMN skip_reason VARCHAR(50)")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("CREATE INDEX IF NOT EXISTS idx_" + sqlRunTable + "_job_name_scheduled_at")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	store := NewSQLHistoryStore(db)
+
+	scheduledAt := time.Date(2021, time.January, 31, 10, 0, 0, 0, time.UTC)
+	run := &JobRun{ID: "run-1", JobName: "job", Trigger: TriggerSchedule, ScheduledAt: scheduledAt, StartedAt: scheduledAt}
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO "+sqlRunTable)+".*"+regexp.QuoteMeta("VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) ON CONFLICT (id) DO UPDATE SET")).
+		WithArgs("run-1", "job", TriggerSchedule, scheduledAt, scheduledAt, int64(0), false, "", "", "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, store.SaveRun(ctx, run))
+
+	// finished run update existing row
+	run.Duration, run.IsFinished, run.Error = time.Second, true, "failed"
+	mock.ExpectExec(regexp.QuoteMeta("ON CONFLICT (id) DO UPDATE SET duration = EXCLUDED.duration")).
+		WithArgs("run-1", "job", TriggerSchedule, scheduledAt, scheduledAt, int64(time.Second), true, "failed", "", "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, store.SaveRun(ctx, run))
+
+	columns := []string{"id", "job_name", "trigger_type", "scheduled_at", "started_at", "duration", "is_finished", "error_message", "trace_id", "skip_reason"}
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE job_name = $1 ORDER BY scheduled_at DESC LIMIT $2")).WithArgs("job", 2).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("run-2", "job", TriggerSchedule, scheduledAt.Add(time.Hour), nil, 0, true, nil, nil, SkipReasonPaused).
+			AddRow("run-1", "job", TriggerSchedule, scheduledAt, scheduledAt, int64(time.Second), true, "failed", nil, nil))
+	runs, err := store.FindRuns(ctx, "job", 2)
+	assert.NoError(t, err)
+	assert.Len(t, runs, 2)
+	assert.Equal(t, "run-2", runs[0].ID)
+	assert.Equal(t, SkipReasonPaused, runs[0].SkipReason)
+	assert.True(t, runs[0].StartedAt.IsZero())
+	assert.Equal(t, "run-1", runs[1].ID)
+	assert.Equal(t, time.Second, runs[1].Duration)
+	assert.Equal(t, "failed", runs[1].Error)
+
+	mock.ExpectQuery(regexp.QuoteMeta("ORDER BY scheduled_at DESC LIMIT $2")).WithArgs("unknown", 1).
+		WillReturnRows(sqlmock.NewRows(columns))
+	lastRun, err := store.FindLastRun(ctx, "unknown")
+	assert.NoError(t, err)
+	assert.Nil(t, lastRun)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// mysql use "?" placeholder and upsert with ON DUPLICATE KEY
+	store = &sqlHistoryStore{db: db, SQLDialect: &candiutils.SQLDialect{DB: db}}
+	mock.ExpectExec(regexp.QuoteMeta("VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE duration = VALUES(duration)")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, store.SaveRun(ctx, run))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
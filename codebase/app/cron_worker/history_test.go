@@ -0,0 +1,163 @@
+package cronworker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type inMemHistoryStore struct {
+	mu   sync.Mutex
+	runs map[string]JobRun
+}
+
+func (s *inMemHistoryStore) SaveRun(ctx context.Context, run *JobRun) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runs[run.ID] = *run
+	return nil
+}
+
+func (s *inMemHistoryStore) FindLastRun(ctx context.Context, jobName string) (*JobRun, error) {
+	runs, _ := s.FindRuns(ctx, jobName, 1)
+	if len(runs) == 0 {
+		return nil, nil
+	}
+	return &runs[0], nil
+}
+
+func (s *inMemHistoryStore) FindRuns(ctx context.Context, jobName string, limit int) (runs []JobRun, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, run := range s.runs {
+		if run.JobName == jobName {
+			runs = append(runs, run)
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].ScheduledAt.After(runs[j].ScheduledAt) })
+	if len(runs) > limit {
+		runs = runs[:limit]
+	}
+	return runs, nil
+}
+
+func TestMissedActivations(t *testing.T) {
+	now := time.Date(2021, time.March, 15, 10, 30, 0, 0, time.UTC)
+	lastRun := &JobRun{ScheduledAt: time.Date(2021, time.March, 15, 7, 0, 0, 0, time.UTC)}
+
+	job := &Job{location: time.UTC}
+	job.schedule, _ = parseSchedule("0 * * * *", now)
+	assert.Empty(t, job.missedActivations(lastRun, now))
+
+	job.Handler.MisfirePolicy = MisfirePolicyRunOnce
+	assert.Equal(t, []time.Time{time.Date(2021, time.March, 15, 10, 0, 0, 0, time.UTC)}, job.missedActivations(lastRun, now))
+	assert.Empty(t, job.missedActivations(nil, now))
+
+	job.Handler.MisfirePolicy = MisfirePolicyRunAll
+	assert.Equal(t, []time.Time{
+		time.Date(2021, time.March, 15, 8, 0, 0, 0, time.UTC),
+		time.Date(2021, time.March, 15, 9, 0, 0, 0, time.UTC),
+		time.Date(2021, time.March, 15, 10, 0, 0, 0, time.UTC),
+	}, job.missedActivations(lastRun, now))
+
+	job.schedule, _ = parseSchedule("1s", now)
+	activations := job.missedActivations(lastRun, now)
+	assert.Len(t, activations, maxMisfireRuns)
+	assert.Equal(t, now, activations[maxMisfireRuns-1])
+
+	assert.Error(t, validateMisfirePolicy("run_twice"))
+}
+
+func TestRunMisfiredJobs(t *testing.T) {
+	store := &inMemHistoryStore{runs: make(map[string]JobRun)}
+	historyStore, semaphore = store, make(chan struct{}, 2)
+	defer func() {
+		for _, job := range activeJobs {
+			job.timer.Stop()
+		}
+		activeJobs, workers, historyStore, semaphore = nil, nil, nil, nil
+	}()
+
+	var (
+		mu        sync.Mutex
+		totalCall = map[string]int{}
+	)
+	handler := func(ctx context.Context, message []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		totalCall[string(message)]++
+		if string(message) == MisfirePolicyRunOnce {
+			return errors.New("failed")
+		}
+		return nil
+	}
+
+	lastScheduledAt := time.Now().UTC().Add(-3*time.Hour - time.Minute).Truncate(time.Hour)
+	for _, policy := range []string{MisfirePolicySkip, MisfirePolicyRunOnce, MisfirePolicyRunAll} {
+		var group types.WorkerHandlerGroup
+		group.Add(policy, handler, types.WorkerHandlerOptionMisfirePolicy(policy))
+		assert.NoError(t, AddJob(Job{HandlerName: policy, Params: policy, Interval: "0 * * * *", Timezone: "UTC", Handler: group.Handlers[0]}))
+		store.SaveRun(context.Background(), &JobRun{ID: policy, JobName: policy, ScheduledAt: lastScheduledAt, StartedAt: lastScheduledAt})
+	}
+	var group types.WorkerHandlerGroup
+	group.Add("invalid", handler, types.WorkerHandlerOptionMisfirePolicy("invalid"))
+	assert.Error(t, AddJob(Job{HandlerName: "invalid", Interval: "0 * * * *", Handler: group.Handlers[0]}))
+
+	c := &cronWorker{ctx: context.Background(), stopped: make(chan struct{})}
+	c.runMisfiredJobs()
+	c.wg.Wait()
+
+	assert.Equal(t, map[string]int{"run_once": 1, "run_all": 3}, totalCall)
+	runs, err := GetJobHistory(context.Background(), "run_once", 10)
+	assert.NoError(t, err)
+	assert.Len(t, runs, 2)
+	assert.Equal(t, TriggerMisfire, runs[0].Trigger)
+	assert.Equal(t, lastScheduledAt.Add(3*time.Hour), runs[0].ScheduledAt.UTC())
+	assert.True(t, runs[0].IsFinished)
+	assert.Equal(t, "failed", runs[0].Error)
+
+	detail, _ := GetJobDetail("run_once")
+	assert.Equal(t, "failed", detail.LastError)
+	detail, _ = GetJobDetail("skip")
+	assert.Equal(t, 0, detail.TotalRun)
+	assert.Equal(t, lastScheduledAt, detail.LastRunAt.UTC())
+
+	// last run already up to date
+	c.runMisfiredJobs()
+	c.wg.Wait()
+	assert.Equal(t, map[string]int{"run_once": 1, "run_all": 3}, totalCall)
+
+	rec := httptest.NewRecorder()
+	newDashboardHandler(nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/cron/jobs/run_all/history?limit=2", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"trigger":"misfire"`)
+	_, err = GetJobHistory(context.Background(), "unknown", 10)
+	assert.True(t, errors.Is(err, ErrJobNotFound))
+
+	// missed activations of paused job is skipped and saved to history, not evaluated as misfire again
+	group = types.WorkerHandlerGroup{}
+	group.Add("paused", handler, types.WorkerHandlerOptionMisfirePolicy(MisfirePolicyRunAll))
+	assert.NoError(t, AddJob(Job{HandlerName: "paused", Params: "paused", Interval: "0 * * * *", Timezone: "UTC", Handler: group.Handlers[0]}))
+	store.SaveRun(context.Background(), &JobRun{ID: "paused", JobName: "paused", ScheduledAt: lastScheduledAt, StartedAt: lastScheduledAt})
+	assert.NoError(t, PauseJob("paused"))
+	c.runMisfiredJobs()
+	c.wg.Wait()
+	assert.Zero(t, totalCall["paused"])
+	runs, _ = GetJobHistory(context.Background(), "paused", 10)
+	assert.Len(t, runs, 4)
+	assert.Equal(t, SkipReasonPaused, runs[0].SkipReason)
+	assert.Equal(t, lastScheduledAt.Add(3*time.Hour), runs[0].ScheduledAt.UTC())
+
+	assert.NoError(t, ResumeJob("paused"))
+	c.runMisfiredJobs()
+	c.wg.Wait()
+	assert.Zero(t, totalCall["paused"])
+}
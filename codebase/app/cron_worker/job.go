@@ -7,26 +7,70 @@ import (
 	"sync"
 	"time"
 
+	"github.com/golangid/candi/candiutils"
 	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/config/env"
 )
 
-// Job model
+// Job model, schedule timezone default from handler option or CRON_WORKER_TIMEZONE environment (local time if empty)
 type Job struct {
-	HandlerName     string              `json:"handler_name"`
-	Interval        string              `json:"interval"`
-	Handler         types.WorkerHandler `json:"-"`
-	Params          string              `json:"params"`
-	WorkerIndex     int                 `json:"worker_index"`
-	ticker          *time.Ticker
-	currentDuration time.Duration
-	nextDuration    *time.Duration
+	HandlerName string              `json:"handler_name"`
+	Interval    string              `json:"interval"`
+	Timezone    string              `json:"timezone"`
+	Handler     types.WorkerHandler `json:"-"`
+	Params      string              `json:"params"`
+	WorkerIndex int                 `json:"worker_index"`
+	location    *time.Location
+	schedule    candiutils.CronSchedule
+	timer       *time.Timer
+	nextRunAt   time.Time
+
+	// runtime state, guarded by mutex
+	isPaused     bool
+	runningCount int
+	queued       *queuedRun
+	totalRun     int
+	lastRunAt    time.Time
+	lastDuration time.Duration
+	lastError    string
+}
+
+// JobDetail runtime state of cron job
+type JobDetail struct {
+	JobName      string     `json:"job_name"`
+	Interval     string     `json:"interval"`
+	Timezone     string     `json:"timezone"`
+	Params       string     `json:"params"`
+	IsPaused     bool       `json:"is_paused"`
+	IsRunning    bool       `json:"is_running"`
+	IsQueued     bool       `json:"is_queued"`
+	TotalRun     int        `json:"total_run"`
+	NextRunAt    *time.Time `json:"next_run_at,omitempty"`
+	LastRunAt    *time.Time `json:"last_run_at,omitempty"`
+	LastDuration string     `json:"last_duration,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
 }
 
+// triggerJobBuffer max pending triggered jobs before executed by worker
+const triggerJobBuffer = 10
+
 var (
 	activeJobs                                                              []*Job
 	workers                                                                 []reflect.SelectCase
 	refreshWorkerNotif, shutdown, semaphore, startWorkerCh, releaseWorkerCh chan struct{}
+	triggerJobCh                                                            chan *Job
 	mutex                                                                   sync.Mutex
+	// isActiveScheduler true if this instance run the schedule (hold the consul lock when using consul), guarded by mutex
+	isActiveScheduler bool
+
+	// neverActivated channel for job without next activation time
+	neverActivated = make(chan time.Time)
+
+	// ErrJobNotFound error when cron job with given name is not registered
+	ErrJobNotFound = errors.New("cron job not found")
+	// ErrNotActiveScheduler error when trigger job in instance which is not running the schedule
+	// (consul lock is held by another instance)
+	ErrNotActiveScheduler = errors.New("cron worker in this instance is not the active scheduler")
 )
 
 // GetActiveJobs get registered jobs
@@ -34,6 +78,109 @@ func GetActiveJobs() []*Job {
 	return activeJobs
 }
 
+// NextRunAt get next activation time of job, zero if job never activated again
+func (j *Job) NextRunAt() time.Time {
+	return j.nextRunAt
+}
+
+// GetJobDetails get runtime state of all registered jobs
+func GetJobDetails() []JobDetail {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	details := make([]JobDetail, 0, len(activeJobs))
+	for _, job := range activeJobs {
+		details = append(details, job.detail())
+	}
+	return details
+}
+
+// GetJobDetail get runtime state of job with given name
+func GetJobDetail(jobName string) (JobDetail, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	job, err := findJob(jobName)
+	if err != nil {
+		return JobDetail{}, err
+	}
+	return job.detail(), nil
+}
+
+// TriggerJob execute job with given name (also paused job) in this instance, schedule of job is not changed.
+// Triggered job is buffered and executed by scheduler loop, return ErrNotActiveScheduler if this instance is not running
+// the schedule (with consul, call the instance which hold the lock). If this instance release the lock before buffered job
+// executed (consul rebalance), job is executed after this instance acquire the lock again
+func TriggerJob(jobName string) error {
+	mutex.Lock()
+	job, err := findJob(jobName)
+	isActive := isActiveScheduler
+	mutex.Unlock()
+	if err != nil {
+		return err
+	}
+	if triggerJobCh == nil {
+		return errors.New("cron worker is not running")
+	}
+	if !isActive {
+		return ErrNotActiveScheduler
+	}
+
+	select {
+	case triggerJobCh <- job:
+		return nil
+	default:
+		return fmt.Errorf("too many pending triggered jobs (max %d)", triggerJobBuffer)
+	}
+}
+
+// PauseJob pause job with given name, scheduled activation is skipped until job resumed. Pause state is only kept
+// in this process (not shared with other instances and reset when restarted)
+func PauseJob(jobName string) error {
+	return setJobPaused(jobName, true)
+}
+
+// ResumeJob resume paused job with given name
+func ResumeJob(jobName string) error {
+	return setJobPaused(jobName, false)
+}
+
+// setActiveScheduler mark this instance is running the schedule or not
+func setActiveScheduler(isActive bool) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	isActiveScheduler = isActive
+}
+
+func setJobPaused(jobName string, isPaused bool) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	job, err := findJob(jobName)
+	if err != nil {
+		return err
+	}
+	job.isPaused = isPaused
+	return nil
+}
+
+// UpdateJobSchedule change interval (and timezone if not empty) of job with given name,
+// next activation time is calculated from now
+func UpdateJobSchedule(jobName, newInterval, newTimezone string) error {
+	mutex.Lock()
+	job, err := findJob(jobName)
+	if err == nil {
+		err = job.updateSchedule(newInterval, newTimezone)
+	}
+	mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	notifyRefreshWorker()
+	return nil
+}
+
 // UpdateIntervalActiveJob update active job
 func UpdateIntervalActiveJob(jobNumber int, newInterval string) (err error) {
 	defer func() {
@@ -43,31 +190,22 @@ func UpdateIntervalActiveJob(jobNumber int, newInterval string) (err error) {
 	}()
 
 	mutex.Lock()
-	defer mutex.Unlock()
-
-	job := activeJobs[jobNumber]
-	job.Interval = newInterval
-	job.nextDuration = nil
-
-	duration, errParse := time.ParseDuration(newInterval)
-	if errParse != nil {
-		durationParser, nextDuration, errParse := parseAtTime(newInterval)
-		if errParse != nil {
-			return errParse
-		}
-		job.nextDuration = &nextDuration
-		duration = durationParser
+	if jobNumber < 0 || jobNumber >= len(activeJobs) {
+		mutex.Unlock()
+		return fmt.Errorf("job number %d not found", jobNumber)
+	}
+	err = activeJobs[jobNumber].updateSchedule(newInterval, "")
+	mutex.Unlock()
+	if err != nil {
+		return err
 	}
 
-	job.ticker.Stop()
-	job.ticker = time.NewTicker(duration)
-	workers[job.WorkerIndex].Chan = reflect.ValueOf(job.ticker.C)
-	refreshWorkerNotif <- struct{}{}
+	notifyRefreshWorker()
 
 	return
 }
 
-// AddJob to cron worker
+// AddJob to cron worker, job name must unique
 func AddJob(job Job) error {
 	mutex.Lock()
 	defer mutex.Unlock()
@@ -78,33 +216,175 @@ func AddJob(job Job) error {
 	if job.HandlerName == "" {
 		return errors.New("handler name cannot empty")
 	}
+	if _, err := findJob(job.HandlerName); err == nil {
+		return fmt.Errorf("handler name '%s' already registered", job.HandlerName)
+	}
+	if err := validateMisfirePolicy(job.Handler.MisfirePolicy); err != nil {
+		return err
+	}
+	if err := validateOverlapPolicy(job.Handler.OverlapPolicy); err != nil {
+		return err
+	}
 
-	duration, err := time.ParseDuration(job.Interval)
+	if job.Timezone == "" {
+		job.Timezone = job.Handler.Timezone
+	}
+	if job.Timezone == "" {
+		job.Timezone = env.BaseEnv().CronWorkerTimezone
+	}
+	location, err := loadLocation(job.Timezone)
 	if err != nil {
-		durationParser, nextDuration, err := parseAtTime(job.Interval)
-		if err != nil {
-			return err
-		}
-		job.nextDuration = &nextDuration
-		duration = durationParser
+		return err
+	}
+	job.location = location
+
+	schedule, err := parseSchedule(job.Interval, time.Now().In(job.location))
+	if err != nil {
+		return err
 	}
 
-	job.currentDuration = duration
-	job.ticker = time.NewTicker(duration)
+	job.schedule = schedule
 	job.WorkerIndex = len(workers)
+	workers = append(workers, reflect.SelectCase{Dir: reflect.SelectRecv})
+	job.startTimer(time.Now())
 
 	activeJobs = append(activeJobs, &job)
-	workers = append(workers, reflect.SelectCase{
-		Dir: reflect.SelectRecv, Chan: reflect.ValueOf(job.ticker.C),
-	})
 
 	return nil
 }
 
+// findJob find registered job by name, must called with mutex locked
+func findJob(jobName string) (*Job, error) {
+	for _, job := range activeJobs {
+		if job.HandlerName == jobName {
+			return job, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: '%s'", ErrJobNotFound, jobName)
+}
+
+// loadLocation load timezone with IANA name, local time if empty
+func loadLocation(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.Local, nil
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone: %v", err)
+	}
+	return location, nil
+}
+
+// updateSchedule replace schedule and restart timer, must called with mutex locked
+func (j *Job) updateSchedule(newInterval, newTimezone string) error {
+	location := j.location
+	if newTimezone != "" {
+		var err error
+		if location, err = loadLocation(newTimezone); err != nil {
+			return err
+		}
+	}
+	schedule, err := parseSchedule(newInterval, time.Now().In(location))
+	if err != nil {
+		return err
+	}
+
+	j.Interval, j.schedule, j.location = newInterval, schedule, location
+	if newTimezone != "" {
+		j.Timezone = newTimezone
+	}
+	j.timer.Stop()
+	j.startTimer(time.Now())
+	return nil
+}
+
+// detail get runtime state of job, must called with mutex locked
+func (j *Job) detail() JobDetail {
+	detail := JobDetail{
+		JobName:   j.HandlerName,
+		Interval:  j.Interval,
+		Timezone:  j.location.String(),
+		Params:    j.Params,
+		IsPaused:  j.isPaused,
+		IsRunning: j.runningCount > 0,
+		IsQueued:  j.queued != nil,
+		TotalRun:  j.totalRun,
+		LastError: j.lastError,
+	}
+	if !j.nextRunAt.IsZero() {
+		nextRunAt := j.nextRunAt
+		detail.NextRunAt = &nextRunAt
+	}
+	if !j.lastRunAt.IsZero() {
+		lastRunAt := j.lastRunAt.In(j.location)
+		detail.LastRunAt = &lastRunAt
+		detail.LastDuration = j.lastDuration.String()
+	}
+	return detail
+}
+
+// markStarted record job execution is started
+func (j *Job) markStarted() (startTime time.Time) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	startTime = time.Now()
+	j.totalRun++
+	j.lastRunAt = startTime
+	return startTime
+}
+
+// markFinished record result of job execution
+func (j *Job) markFinished(startTime time.Time, err error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	j.lastDuration = time.Since(startTime)
+	j.lastError = ""
+	if err != nil {
+		j.lastError = err.Error()
+	}
+}
+
+// startTimer create new timer until next activation time after given time (in job timezone), must called with mutex locked
+func (j *Job) startTimer(now time.Time) {
+	j.nextRunAt = j.schedule.Next(now.In(j.location))
+	if j.nextRunAt.IsZero() {
+		j.timer = time.NewTimer(0)
+		j.timer.Stop()
+		workers[j.WorkerIndex].Chan = reflect.ValueOf(neverActivated)
+		return
+	}
+	j.timer = time.NewTimer(j.nextRunAt.Sub(now))
+	workers[j.WorkerIndex].Chan = reflect.ValueOf(j.timer.C)
+}
+
+// resetTimer reset fired timer until next activation time after given time (in job timezone), must called with mutex locked
+func (j *Job) resetTimer(now time.Time) {
+	j.nextRunAt = j.schedule.Next(now.In(j.location))
+	if j.nextRunAt.IsZero() {
+		workers[j.WorkerIndex].Chan = reflect.ValueOf(neverActivated)
+		return
+	}
+	j.timer.Reset(j.nextRunAt.Sub(now))
+}
+
+// notifyRefreshWorker wake up running worker select for reload changed channel,
+// not running worker will load changed channel in next select
+func notifyRefreshWorker() {
+	select {
+	case refreshWorkerNotif <- struct{}{}:
+	default:
+	}
+}
+
 func startAllJob() {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	now := time.Now()
 	for _, job := range activeJobs {
-		job.ticker = time.NewTicker(job.currentDuration)
-		workers[job.WorkerIndex].Chan = reflect.ValueOf(job.ticker.C)
+		job.startTimer(now)
 	}
 	go func() {
 		refreshWorkerNotif <- struct{}{}
@@ -112,7 +392,10 @@ func startAllJob() {
 }
 
 func stopAllJob() {
+	mutex.Lock()
+	defer mutex.Unlock()
+
 	for _, job := range activeJobs {
-		job.ticker.Stop()
+		job.timer.Stop()
 	}
 }
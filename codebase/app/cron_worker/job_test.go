@@ -0,0 +1,85 @@
+package cronworker
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddJobTimezone(t *testing.T) {
+	defer func() {
+		for _, job := range activeJobs {
+			job.timer.Stop()
+		}
+		activeJobs, workers = nil, nil
+	}()
+
+	var group types.WorkerHandlerGroup
+	group.Add("job", func(ctx context.Context, message []byte) error { return nil }, types.WorkerHandlerOptionTimezone("Asia/Jakarta"))
+
+	err := AddJob(Job{HandlerName: "job", Interval: "0 2 * * *", Handler: group.Handlers[0]})
+	assert.NoError(t, err)
+	job := activeJobs[0]
+	assert.Equal(t, "Asia/Jakarta", job.Timezone)
+	assert.Equal(t, "Asia/Jakarta", job.NextRunAt().Location().String())
+	assert.Equal(t, 2, job.NextRunAt().Hour())
+	assert.Equal(t, 19, job.NextRunAt().UTC().Hour())
+
+	err = AddJob(Job{HandlerName: "job-utc", Interval: "0 2 * * *", Timezone: "UTC", Handler: group.Handlers[0]})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, activeJobs[1].NextRunAt().UTC().Hour())
+
+	err = AddJob(Job{HandlerName: "job-invalid", Interval: "0 2 * * *", Timezone: "Mars/Olympus", Handler: group.Handlers[0]})
+	assert.Error(t, err)
+	assert.Len(t, activeJobs, 2)
+}
+
+func TestServeUpdateJobSchedule(t *testing.T) {
+	refreshWorkerNotif, shutdown, triggerJobCh = make(chan struct{}), make(chan struct{}), make(chan *Job, triggerJobBuffer)
+	semaphore, startWorkerCh, releaseWorkerCh = make(chan struct{}, 2), make(chan struct{}), make(chan struct{})
+	workers = []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(shutdown)},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(refreshWorkerNotif)},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(triggerJobCh)},
+	}
+	defer func() {
+		activeJobs, workers, triggerJobCh = nil, nil, nil
+	}()
+
+	executed := make(chan struct{}, 10)
+	var group types.WorkerHandlerGroup
+	group.Add("job", func(ctx context.Context, message []byte) error {
+		executed <- struct{}{}
+		return nil
+	})
+	assert.NoError(t, AddJob(Job{HandlerName: "job", Interval: "1h", Handler: group.Handlers[0]}))
+
+	c := &cronWorker{ctx: context.Background(), ctxCancelFunc: func() {}, stopped: make(chan struct{})}
+	served := make(chan struct{})
+	go func() {
+		c.Serve()
+		close(served)
+	}()
+
+	// schedule changed while worker select on job timer
+	for _, interval := range []string{"2h", "1s", "1h"} {
+		assert.NoError(t, UpdateJobSchedule("job", interval, ""))
+		assert.NoError(t, UpdateIntervalActiveJob(0, interval))
+	}
+	// trigger rejected until worker run the schedule
+	err := TriggerJob("job")
+	for errors.Is(err, ErrNotActiveScheduler) {
+		time.Sleep(time.Millisecond)
+		err = TriggerJob("job")
+	}
+	assert.NoError(t, err)
+	<-executed
+
+	c.Shutdown(context.Background())
+	<-served
+}
@@ -0,0 +1,41 @@
+package cronworker
+
+import "github.com/golangid/candi/middleware"
+
+type (
+	option struct {
+		DashboardPort uint16
+		DashboardAuth *middleware.DashboardAuth
+		HistoryStore  HistoryStore
+	}
+
+	// OptionFunc type
+	OptionFunc func(*option)
+)
+
+// SetDashboardPort option func, serve management REST API (list, trigger, pause, resume, and change schedule of job)
+// in given port, API is disabled if port is zero
+func SetDashboardPort(port uint16) OptionFunc {
+	return func(o *option) {
+		o.DashboardPort = port
+	}
+}
+
+// SetDashboardAuth option func, protect management REST API using service middleware (basic or bearer auth with ACL),
+// panic if middleware is nil so API is never served unprotected
+func SetDashboardAuth(auth middleware.DashboardAuth) OptionFunc {
+	if err := auth.Validate(); err != nil {
+		panic("Cron Worker: " + err.Error())
+	}
+	return func(o *option) {
+		o.DashboardAuth = &auth
+	}
+}
+
+// SetHistoryStore option func, persist history of job execution (see NewMongoHistoryStore, NewSQLHistoryStore,
+// and NewRedisHistoryStore), last run in history store is used for evaluate job misfire policy on startup
+func SetHistoryStore(store HistoryStore) OptionFunc {
+	return func(o *option) {
+		o.HistoryStore = store
+	}
+}
@@ -0,0 +1,70 @@
+package cronworker
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// OverlapPolicyAllow new run of job is executed even if previous run is still running (default)
+	OverlapPolicyAllow = "allow"
+	// OverlapPolicySkip new run of job is skipped if previous run is still running
+	OverlapPolicySkip = "skip"
+	// OverlapPolicyQueue new run of job is queued (max one queued run) and executed after previous run finished
+	OverlapPolicyQueue = "queue"
+)
+
+// queuedRun pending run of job with overlap policy queue
+type queuedRun struct {
+	scheduledAt time.Time
+	trigger     string
+}
+
+func validateOverlapPolicy(policy string) error {
+	switch policy {
+	case "", OverlapPolicyAllow, OverlapPolicySkip, OverlapPolicyQueue:
+		return nil
+	}
+	return fmt.Errorf(`invalid overlap policy "%s" (must one of "%s", "%s", "%s")`,
+		policy, OverlapPolicyAllow, OverlapPolicySkip, OverlapPolicyQueue)
+}
+
+// tryStart check pause state (except manual trigger) and overlap policy before job run is executed,
+// return false if run is skipped (with skip reason) or queued (empty skip reason)
+func (j *Job) tryStart(scheduledAt time.Time, trigger string) (ok bool, skipReason string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if j.isPaused && trigger != TriggerManual {
+		return false, SkipReasonPaused
+	}
+	if j.runningCount > 0 {
+		switch j.Handler.OverlapPolicy {
+		case OverlapPolicySkip:
+			return false, SkipReasonOverlap
+		case OverlapPolicyQueue:
+			// only one run is queued, next run is dropped until queued run executed
+			if j.queued != nil {
+				return false, SkipReasonOverlap
+			}
+			j.queued = &queuedRun{scheduledAt: scheduledAt, trigger: trigger}
+			return false, ""
+		}
+	}
+	j.runningCount++
+	return true, ""
+}
+
+// releaseRun release job run after executed, return queued run which must executed next (if not discarded)
+func (j *Job) releaseRun(discardQueued bool) *queuedRun {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	next := j.queued
+	j.queued = nil
+	if next == nil || discardQueued {
+		j.runningCount--
+		return nil
+	}
+	return next
+}
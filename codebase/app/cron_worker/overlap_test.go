@@ -0,0 +1,114 @@
+package cronworker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// isStarted get result of job.tryStart without skip reason
+func isStarted(ok bool, skipReason string) bool {
+	return ok
+}
+
+func TestOverlapPolicy(t *testing.T) {
+	now := time.Now()
+
+	job := &Job{}
+	assert.True(t, isStarted(job.tryStart(now, TriggerSchedule)))
+	assert.True(t, isStarted(job.tryStart(now, TriggerSchedule)))
+	assert.Nil(t, job.releaseRun(false))
+	assert.Nil(t, job.releaseRun(false))
+	assert.Zero(t, job.runningCount)
+
+	job.Handler.OverlapPolicy = OverlapPolicySkip
+	assert.True(t, isStarted(job.tryStart(now, TriggerSchedule)))
+	_, skipReason := job.tryStart(now, TriggerManual)
+	assert.Equal(t, SkipReasonOverlap, skipReason)
+	assert.Nil(t, job.releaseRun(false))
+	assert.True(t, isStarted(job.tryStart(now, TriggerManual)))
+	job.releaseRun(false)
+
+	job.Handler.OverlapPolicy = OverlapPolicyQueue
+	assert.True(t, isStarted(job.tryStart(now, TriggerSchedule)))
+	ok, skipReason := job.tryStart(now.Add(time.Minute), TriggerManual)
+	assert.False(t, ok)
+	assert.Empty(t, skipReason)
+	_, skipReason = job.tryStart(now.Add(2*time.Minute), TriggerSchedule)
+	assert.Equal(t, SkipReasonOverlap, skipReason)
+	assert.True(t, job.detail().IsQueued)
+	next := job.releaseRun(false)
+	assert.Equal(t, &queuedRun{scheduledAt: now.Add(time.Minute), trigger: TriggerManual}, next)
+	assert.Equal(t, 1, job.runningCount)
+	assert.Nil(t, job.releaseRun(false))
+	assert.Zero(t, job.runningCount)
+
+	assert.True(t, isStarted(job.tryStart(now, TriggerSchedule)))
+	assert.False(t, isStarted(job.tryStart(now, TriggerSchedule)))
+	assert.Nil(t, job.releaseRun(true))
+	assert.Zero(t, job.runningCount)
+
+	// paused job only executed by manual trigger
+	job.isPaused = true
+	_, skipReason = job.tryStart(now, TriggerSchedule)
+	assert.Equal(t, SkipReasonPaused, skipReason)
+	_, skipReason = job.tryStart(now, TriggerMisfire)
+	assert.Equal(t, SkipReasonPaused, skipReason)
+	assert.True(t, isStarted(job.tryStart(now, TriggerManual)))
+	assert.Nil(t, job.releaseRun(false))
+
+	assert.Error(t, validateOverlapPolicy("wait"))
+}
+
+func TestExecJobQueueAndTimeout(t *testing.T) {
+	store := &inMemHistoryStore{runs: make(map[string]JobRun)}
+	historyStore = store
+	defer func() { historyStore = nil }()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	var group types.WorkerHandlerGroup
+	group.Add("job", func(ctx context.Context, message []byte) error {
+		started <- struct{}{}
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		return nil
+	}, types.WorkerHandlerOptionOverlapPolicy(OverlapPolicyQueue), types.WorkerHandlerOptionTimeout(time.Second))
+	job := &Job{HandlerName: "job", Handler: group.Handlers[0], location: time.UTC}
+
+	c := &cronWorker{ctx: context.Background(), stopped: make(chan struct{})}
+	now := time.Now()
+	assert.True(t, isStarted(job.tryStart(now, TriggerSchedule)))
+	done := make(chan struct{})
+	go func() {
+		c.execJob(job, now, TriggerSchedule)
+		close(done)
+	}()
+
+	<-started
+	assert.False(t, isStarted(job.tryStart(now, TriggerManual)))
+	release <- struct{}{}
+
+	// queued run executed after previous run finished, then canceled by timeout
+	<-started
+	<-done
+	assert.Zero(t, job.runningCount)
+
+	runs, _ := store.FindRuns(context.Background(), "job", 10)
+	assert.Len(t, runs, 2)
+	var triggers []string
+	for _, run := range runs {
+		triggers = append(triggers, run.Trigger)
+		if run.Trigger == TriggerManual {
+			assert.Equal(t, "job exceeded max execution timeout 1s", run.Error)
+		} else {
+			assert.Empty(t, run.Error)
+		}
+	}
+	assert.ElementsMatch(t, []string{TriggerSchedule, TriggerManual}, triggers)
+	assert.Equal(t, 2, job.detail().TotalRun)
+}
@@ -6,87 +6,145 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/golangid/candi/candiutils"
 )
 
 const (
-	oneDay   = 24 * time.Hour
-	oneWeek  = 7 * oneDay
-	oneMonth = 30 * oneDay
-	oneYear  = 12 * oneMonth
-
 	daily   = "daily"
 	weekly  = "weekly"
 	monthly = "monthly"
 	yearly  = "yearly"
 )
 
-// parseAtTime with input format HH:mm:ss, will repeat every day (24 hours) in the same time
-func parseAtTime(t string) (duration, nextDuration time.Duration, err error) {
+// atTimeInterval schedule start at given time and repeated every interval
+type atTimeInterval struct {
+	start    time.Time
+	interval time.Duration
+}
+
+// Next implement candiutils.CronSchedule
+func (a *atTimeInterval) Next(t time.Time) time.Time {
+	if t.Before(a.start) {
+		return a.start
+	}
+	return a.start.Add((t.Sub(a.start)/a.interval + 1) * a.interval)
+}
+
+// atTimeDayOfMonth schedule at given day of month (every month if month is zero), day is clamped to last day of
+// shorter month (example: day 31 run at 30 April and 28/29 February, day 29 February run at 28 February in non leap year)
+type atTimeDayOfMonth struct {
+	day, hour, min, sec int
+	month               time.Month
+}
+
+// Next implement candiutils.CronSchedule
+func (a *atTimeDayOfMonth) Next(t time.Time) time.Time {
+	year, month := t.Year(), t.Month()
+	if a.month != 0 {
+		month = a.month
+	}
+	for i := 0; i < 24; i++ {
+		// first day of candidate month is normalized by time.Date
+		firstDay := time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+		day := a.day
+		if lastDay := firstDay.AddDate(0, 1, -1).Day(); day > lastDay {
+			day = lastDay
+		}
+		next := time.Date(firstDay.Year(), firstDay.Month(), day, a.hour, a.min, a.sec, 0, t.Location())
+		if next.After(t) {
+			return next
+		}
+		if a.month != 0 {
+			year++
+		} else {
+			month++
+		}
+	}
+	return time.Time{}
+}
+
+// parseSchedule parse job interval, allowed format:
+// standard time duration string (example: 10s), custom start time with descriptor (example: 23:00@daily),
+// or standard cron expression (see candiutils.ParseCronExpression)
+func parseSchedule(interval string, now time.Time) (candiutils.CronSchedule, error) {
+	if duration, err := time.ParseDuration(interval); err == nil {
+		if duration <= 0 {
+			return nil, errors.New("interval must greater than zero")
+		}
+		return candiutils.ParseCronExpression("@every " + interval)
+	}
+	if strings.Contains(interval, ":") {
+		return parseAtTime(interval, now)
+	}
+	return candiutils.ParseCronExpression(interval)
+}
+
+// parseAtTime with input format HH:mm:ss@descriptor, will repeat every day (default), week, month, or year
+// in the same time starting from first matched time after now, or repeat every duration from start time today
+// if descriptor is duration string (example: 09:00@1h at 10:30 next run at 11:00).
+// Monthly and yearly repeat in the same day of first activation, clamped to last day of month if month is shorter
+func parseAtTime(t string, now time.Time) (candiutils.CronSchedule, error) {
 
 	withDescriptors := strings.Split(t, "@")
 
 	ts := strings.Split(withDescriptors[0], ":")
 	if len(ts) < 2 || len(ts) > 3 {
-		return 0, 0, errors.New("time format error")
+		return nil, errors.New("time format error")
 	}
 
-	var hour, min, sec int
-	hour, err = strconv.Atoi(ts[0])
+	hour, err := strconv.Atoi(ts[0])
 	if err != nil {
-		return
+		return nil, err
 	}
 
-	min, err = strconv.Atoi(ts[1])
+	min, err := strconv.Atoi(ts[1])
 	if err != nil {
-		return
+		return nil, err
 	}
 
+	var sec int
 	if len(ts) == 3 {
 		if sec, err = strconv.Atoi(ts[2]); err != nil {
-			return
+			return nil, err
 		}
 	}
 
 	if hour < 0 || hour > 23 || min < 0 || min > 59 || sec < 0 || sec > 59 {
-		return 0, 0, errors.New("time format error")
+		return nil, errors.New("time format error")
 	}
 
-	// default value
-	repeatDuration := oneDay
-
-	if len(withDescriptors) > 1 {
-
-		switch withDescriptors[1] {
-		case daily:
-			repeatDuration = oneDay
-		case weekly:
-			repeatDuration = oneWeek
-		case monthly:
-			repeatDuration = oneMonth
-		case yearly:
-			repeatDuration = oneYear
-		default:
-			repeatDuration, err = time.ParseDuration(withDescriptors[1])
-			if err != nil {
-				return 0, 0, fmt.Errorf(`invalid descriptor "%s" (must one of "daily", "weekly", "monthly", "yearly") or duration string`,
-					withDescriptors[1])
-			}
-		}
+	start := time.Date(now.Year(), now.Month(), now.Day(), hour, min, sec, 0, now.Location())
+	atTime := start
+	if !atTime.After(now) {
+		atTime = atTime.AddDate(0, 0, 1)
 	}
 
-	now := time.Now()
-	atTime := time.Date(now.Year(), now.Month(), now.Day(), hour, min, sec, 0, now.Location())
-	if now.Before(atTime) {
-		duration = atTime.Sub(now)
-	} else {
-		duration = repeatDuration - now.Sub(atTime)
+	// default value
+	descriptor := daily
+	if len(withDescriptors) > 1 {
+		descriptor = withDescriptors[1]
 	}
 
-	if duration < 0 {
-		duration *= -1
+	// repeat in calendar unit from first activation time
+	var expr string
+	switch descriptor {
+	case daily:
+		expr = fmt.Sprintf("%d %d %d * * *", sec, min, hour)
+	case weekly:
+		expr = fmt.Sprintf("%d %d %d * * %d", sec, min, hour, atTime.Weekday())
+	case monthly:
+		return &atTimeDayOfMonth{day: atTime.Day(), hour: hour, min: min, sec: sec}, nil
+	case yearly:
+		return &atTimeDayOfMonth{day: atTime.Day(), month: atTime.Month(), hour: hour, min: min, sec: sec}, nil
+	default:
+		repeatDuration, err := time.ParseDuration(descriptor)
+		if err != nil || repeatDuration <= 0 {
+			return nil, fmt.Errorf(`invalid descriptor "%s" (must one of "daily", "weekly", "monthly", "yearly") or duration string`,
+				descriptor)
+		}
+		// repeat from start time today, so that next run is not delayed to tomorrow when start time has passed
+		return &atTimeInterval{start: start, interval: repeatDuration}, nil
 	}
-
-	nextDuration = repeatDuration
-
-	return
+	return candiutils.ParseCronExpression(expr)
 }
@@ -0,0 +1,103 @@
+package cronworker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSchedule(t *testing.T) {
+	now := time.Date(2021, time.January, 31, 10, 0, 0, 0, time.UTC) // sunday
+
+	testCase := map[string]struct {
+		interval string
+		expected []time.Time
+		wantErr  bool
+	}{
+		"Testcase #1: duration": {
+			interval: "90s",
+			expected: []time.Time{time.Date(2021, time.January, 31, 10, 1, 30, 0, time.UTC), time.Date(2021, time.January, 31, 10, 3, 0, 0, time.UTC)},
+		},
+		"Testcase #2: at time default daily": {
+			interval: "09:30",
+			expected: []time.Time{time.Date(2021, time.February, 1, 9, 30, 0, 0, time.UTC), time.Date(2021, time.February, 2, 9, 30, 0, 0, time.UTC)},
+		},
+		"Testcase #3: at time weekly": {
+			interval: "23:00:10@weekly",
+			expected: []time.Time{time.Date(2021, time.January, 31, 23, 0, 10, 0, time.UTC), time.Date(2021, time.February, 7, 23, 0, 10, 0, time.UTC)},
+		},
+		"Testcase #4: at time monthly": {
+			interval: "11:00@monthly",
+			expected: []time.Time{time.Date(2021, time.January, 31, 11, 0, 0, 0, time.UTC), time.Date(2021, time.February, 28, 11, 0, 0, 0, time.UTC)},
+		},
+		"Testcase #5: at time with repeat duration": {
+			interval: "11:00@10m",
+			expected: []time.Time{time.Date(2021, time.January, 31, 11, 0, 0, 0, time.UTC), time.Date(2021, time.January, 31, 11, 10, 0, 0, time.UTC)},
+		},
+		"Testcase #6: cron expression": {
+			interval: "0 2 1 * *",
+			expected: []time.Time{time.Date(2021, time.February, 1, 2, 0, 0, 0, time.UTC), time.Date(2021, time.March, 1, 2, 0, 0, 0, time.UTC)},
+		},
+		"Testcase #7: cron expression last day of month": {
+			interval: "0 0 L * *",
+			expected: []time.Time{time.Date(2021, time.February, 28, 0, 0, 0, 0, time.UTC), time.Date(2021, time.March, 31, 0, 0, 0, 0, time.UTC)},
+		},
+		"Testcase #8: at time with repeat duration after start time passed": {
+			interval: "09:00@45m",
+			expected: []time.Time{time.Date(2021, time.January, 31, 10, 30, 0, 0, time.UTC), time.Date(2021, time.January, 31, 11, 15, 0, 0, time.UTC)},
+		},
+		"Testcase #9: sub second duration": {
+			interval: "500ms",
+			expected: []time.Time{time.Date(2021, time.January, 31, 10, 0, 0, int(500*time.Millisecond), time.UTC), time.Date(2021, time.January, 31, 10, 0, 1, 0, time.UTC)},
+		},
+		"Testcase #10: invalid descriptor": {
+			interval: "11:00@fortnightly", wantErr: true,
+		},
+		"Testcase #11: invalid time": {
+			interval: "25:00@daily", wantErr: true,
+		},
+		"Testcase #12: invalid cron expression": {
+			interval: "0 0 32 * *", wantErr: true,
+		},
+	}
+
+	for name, tt := range testCase {
+		t.Run(name, func(t *testing.T) {
+			schedule, err := parseSchedule(tt.interval, now)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			next := now
+			for _, expected := range tt.expected {
+				next = schedule.Next(next)
+				assert.Equal(t, expected, next)
+			}
+		})
+	}
+}
+
+func TestAtTimeDayOfMonth(t *testing.T) {
+	monthly := &atTimeDayOfMonth{day: 31, hour: 11}
+	next := time.Date(2021, time.January, 31, 11, 0, 0, 0, time.UTC)
+	for _, expected := range []time.Time{
+		time.Date(2021, time.February, 28, 11, 0, 0, 0, time.UTC),
+		time.Date(2021, time.March, 31, 11, 0, 0, 0, time.UTC),
+		time.Date(2021, time.April, 30, 11, 0, 0, 0, time.UTC),
+	} {
+		next = monthly.Next(next)
+		assert.Equal(t, expected, next)
+	}
+
+	yearly, err := parseAtTime("00:00@yearly", time.Date(2020, time.February, 28, 12, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	next = yearly.Next(time.Date(2020, time.February, 28, 12, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2020, time.February, 29, 0, 0, 0, 0, time.UTC), next)
+	next = yearly.Next(next)
+	assert.Equal(t, time.Date(2021, time.February, 28, 0, 0, 0, 0, time.UTC), next)
+	next = yearly.Next(time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC), next)
+}
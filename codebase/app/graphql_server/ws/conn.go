@@ -5,7 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 	"time"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/candishared"
 )
 
 type operationMessageType string
@@ -47,7 +52,9 @@ type startMessagePayload struct {
 	Variables     map[string]interface{} `json:"variables"`
 }
 
-type initMessagePayload struct{}
+// initMessagePayload connection params from client, browser cannot set header in websocket handshake request
+// so that authorization is sent in connection params
+type initMessagePayload map[string]interface{}
 
 // GraphQLService interface
 type GraphQLService interface {
@@ -165,6 +172,7 @@ func (conn *connection) readLoop(ctx context.Context, send sendFunc) {
 				send("", typeConnectionError, ep)
 				continue
 			}
+			ctx = initMsg.withHTTPHeader(ctx)
 			send("", typeConnectionAck, nil)
 
 		case typeStart:
@@ -237,6 +245,28 @@ func (conn *connection) readLoop(ctx context.Context, send sendFunc) {
 	}
 }
 
+// withHTTPHeader set authorization from connection params to http header in context, used by graphql middleware
+func (p initMessagePayload) withHTTPHeader(ctx context.Context) context.Context {
+	var authorization string
+	for key, value := range p {
+		if s, ok := value.(string); ok && strings.EqualFold(key, candihelper.HeaderAuthorization) {
+			authorization = s
+		}
+	}
+	if authorization == "" {
+		return ctx
+	}
+
+	// header of handshake request is not modified
+	header, _ := ctx.Value(candishared.ContextKeyHTTPHeader).(http.Header)
+	header = header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set(candihelper.HeaderAuthorization, authorization)
+	return candishared.SetToContext(ctx, candishared.ContextKeyHTTPHeader, header)
+}
+
 func errPayload(err error) json.RawMessage {
 	b, _ := json.Marshal(struct {
 		Message string `json:"message"`
@@ -0,0 +1,78 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/candishared"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeConnection struct {
+	mu       sync.Mutex
+	messages []operationMessage
+	written  []interface{}
+}
+
+func (f *fakeConnection) Close() error                       { return nil }
+func (f *fakeConnection) SetReadLimit(limit int64)           {}
+func (f *fakeConnection) SetWriteDeadline(t time.Time) error { return nil }
+
+func (f *fakeConnection) ReadJSON(v interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.messages) == 0 {
+		return io.EOF
+	}
+	*v.(*operationMessage), f.messages = f.messages[0], f.messages[1:]
+	return nil
+}
+
+func (f *fakeConnection) WriteJSON(v interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.written = append(f.written, v)
+	return nil
+}
+
+type fakeService struct {
+	header http.Header
+}
+
+func (f *fakeService) Subscribe(ctx context.Context, document string, operationName string, variableValues map[string]interface{}) (<-chan interface{}, error) {
+	f.header, _ = candishared.GetValueFromContext(ctx, candishared.ContextKeyHTTPHeader).(http.Header)
+	return nil, errors.New("closed")
+}
+
+func TestConnectAuthorizationFromInitPayload(t *testing.T) {
+	initPayload, _ := json.Marshal(map[string]interface{}{"authorization": "Bearer token"})
+	startPayload, _ := json.Marshal(startMessagePayload{Query: "subscription { listen_task }"})
+	ws := &fakeConnection{messages: []operationMessage{
+		{Type: typeConnectionInit, Payload: initPayload},
+		{ID: "1", Type: typeStart, Payload: startPayload},
+	}}
+	service := &fakeService{}
+
+	// authorization in connection params used as header because browser cannot set header of handshake request
+	handshakeHeader := http.Header{"X-Request-Id": []string{"request"}}
+	Connect(candishared.SetToContext(context.Background(), candishared.ContextKeyHTTPHeader, handshakeHeader), ws, service)
+	assert.Equal(t, "Bearer token", service.header.Get(candihelper.HeaderAuthorization))
+	assert.Equal(t, "request", service.header.Get("X-Request-Id"))
+	assert.Empty(t, handshakeHeader.Get(candihelper.HeaderAuthorization))
+
+	// header of handshake request is kept without authorization in connection params
+	ws = &fakeConnection{messages: []operationMessage{
+		{Type: typeConnectionInit, Payload: json.RawMessage(`{}`)},
+		{ID: "1", Type: typeStart, Payload: startPayload},
+	}}
+	handshakeHeader.Set(candihelper.HeaderAuthorization, "Bearer handshake")
+	Connect(candishared.SetToContext(context.Background(), candishared.ContextKeyHTTPHeader, handshakeHeader), ws, service)
+	assert.Equal(t, "Bearer handshake", service.header.Get(candihelper.HeaderAuthorization))
+}
@@ -0,0 +1,187 @@
+package taskqueueworker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/logger"
+	"github.com/google/uuid"
+)
+
+type (
+	// Batch model, group of jobs with same task which added in one call
+	Batch struct {
+		ID               string    `bson:"_id" json:"_id"`
+		TaskName         string    `bson:"task_name" json:"task_name"`
+		TotalJobs        int       `bson:"total_jobs" json:"total_jobs"`
+		CallbackTaskName string    `bson:"callback_task_name" json:"callback_task_name"`
+		CallbackMaxRetry int       `bson:"callback_max_retry" json:"callback_max_retry"`
+		CreatedAt        time.Time `bson:"created_at" json:"created_at"`
+		FinishedAt       time.Time `bson:"finished_at" json:"finished_at"`
+	}
+
+	// BatchResolver resolver, aggregate progress of all jobs in batch (also used as arguments of callback task)
+	BatchResolver struct {
+		ID         string    `json:"batch_id"`
+		TaskName   string    `json:"task_name"`
+		TotalJobs  int       `json:"total_jobs"`
+		IsFinished bool      `json:"is_finished"`
+		CreatedAt  time.Time `json:"created_at"`
+		FinishedAt time.Time `json:"finished_at"`
+		Detail     struct {
+			Failure   int `json:"failure"`
+			Retrying  int `json:"retrying"`
+			Success   int `json:"success"`
+			Queueing  int `json:"queueing"`
+			Stopped   int `json:"stopped"`
+			Scheduled int `json:"scheduled"`
+		} `json:"detail"`
+	}
+
+	addBatchOption struct {
+		callbackTaskName string
+		callbackMaxRetry int
+		jobOptions       []AddJobOptionFunc
+	}
+
+	// AddBatchOptionFunc type
+	AddBatchOptionFunc func(*addBatchOption)
+)
+
+// AddBatchOptionCallback add batch option func, callback task will be added as new job with batch progress
+// as arguments (see BatchResolver) when all jobs in batch reached final status (success, failure, or stopped),
+// deleted jobs is not counted in batch progress
+func AddBatchOptionCallback(taskName string, maxRetry int) AddBatchOptionFunc {
+	return func(o *addBatchOption) {
+		o.callbackTaskName = taskName
+		o.callbackMaxRetry = maxRetry
+	}
+}
+
+// AddBatchOptionJobOptions add batch option func, given add job options will be applied to all jobs in batch
+func AddBatchOptionJobOptions(opts ...AddJobOptionFunc) AddBatchOptionFunc {
+	return func(o *addBatchOption) {
+		o.jobOptions = append(o.jobOptions, opts...)
+	}
+}
+
+// AddBatch public function for add many jobs with same task under one batch ID
+func AddBatch(taskName string, maxRetry int, argsList [][]byte, opts ...AddBatchOptionFunc) (batchID string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	if len(argsList) == 0 {
+		return "", errors.New("Batch must contains at least one job")
+	}
+
+	var opt addBatchOption
+	for _, o := range opts {
+		o(&opt)
+	}
+	var jobOpt addJobOption
+	for _, o := range opt.jobOptions {
+		o(&jobOpt)
+	}
+	if jobOpt.uniqueKey != "" {
+		return "", errors.New("Unique key option is not supported in batch")
+	}
+
+	workerIndex, err := validateJob(taskName, maxRetry, jobOpt)
+	if err != nil {
+		return "", err
+	}
+	if opt.callbackTaskName != "" {
+		if _, err := validateJob(opt.callbackTaskName, opt.callbackMaxRetry, addJobOption{}); err != nil {
+			return "", fmt.Errorf("invalid callback: %v", err)
+		}
+	}
+	for i, args := range argsList {
+		if err := validateJobArgs(taskName, args); err != nil {
+			return "", fmt.Errorf("job %d: %v", i, err)
+		}
+	}
+
+	ctx := context.Background()
+	batch := &Batch{
+		ID:               uuid.New().String(),
+		TaskName:         taskName,
+		TotalJobs:        len(argsList),
+		CallbackTaskName: opt.callbackTaskName,
+		CallbackMaxRetry: opt.callbackMaxRetry,
+		CreatedAt:        time.Now(),
+	}
+	// batch must be saved before jobs, so that completed batch can be checked when first job finished
+	persistent.SaveBatch(ctx, batch)
+
+	jobOpt.batchID = batch.ID
+	jobs := make([]*Job, len(argsList))
+	for i, args := range argsList {
+		jobs[i] = createJob(taskName, maxRetry, args, jobOpt)
+	}
+	saveAndPushJobs(ctx, jobs, workerIndex)
+
+	return batch.ID, nil
+}
+
+// GetBatch public function for get aggregate progress of all jobs in batch
+func GetBatch(ctx context.Context, batchID string) (res BatchResolver, err error) {
+	batch, err := persistent.FindBatchByID(ctx, batchID)
+	if err != nil {
+		return res, err
+	}
+
+	res.ID, res.TaskName, res.TotalJobs = batch.ID, batch.TaskName, batch.TotalJobs
+	res.IsFinished = !batch.FinishedAt.IsZero()
+	res.CreatedAt = batch.CreatedAt.In(candihelper.AsiaJakartaLocalTime)
+	res.FinishedAt = batch.FinishedAt.In(candihelper.AsiaJakartaLocalTime)
+
+	result := persistent.AggregateAllTaskJob(ctx, Filter{TaskNameList: []string{batch.TaskName}, BatchID: batch.ID})
+	if len(result) > 0 {
+		detail := result[0].Detail
+		res.Detail.Failure, res.Detail.Retrying, res.Detail.Success = detail.Failure, detail.Retrying, detail.Success
+		res.Detail.Queueing, res.Detail.Stopped, res.Detail.Scheduled = detail.Queueing, detail.Stopped, detail.Scheduled
+	}
+	return res, nil
+}
+
+// checkBatchFinished mark batch as finished and add callback job if all jobs in batch reached final status.
+// Batch is finished when no job left with unfinished status, finished jobs may have been deleted
+// (by retention sweeper or clean job) before the last job finished
+func checkBatchFinished(ctx context.Context, batchID string) {
+	if batchID == "" {
+		return
+	}
+
+	res, err := GetBatch(ctx, batchID)
+	if err != nil || res.IsFinished {
+		return
+	}
+	if res.Detail.Queueing+res.Detail.Retrying+res.Detail.Scheduled > 0 {
+		return
+	}
+
+	batch, err := persistent.FindBatchByID(ctx, batchID)
+	if err != nil {
+		return
+	}
+	batch.FinishedAt = time.Now()
+	persistent.SaveBatch(ctx, batch)
+
+	if batch.CallbackTaskName == "" {
+		return
+	}
+	res.IsFinished, res.FinishedAt = true, batch.FinishedAt.In(candihelper.AsiaJakartaLocalTime)
+	args, _ := json.Marshal(res)
+	// unique key prevent callback added more than once when last jobs in batch finished at same time
+	if _, err := AddJobWithOption(batch.CallbackTaskName, batch.CallbackMaxRetry, args,
+		AddJobOptionUniqueKey("batch:"+batch.ID, 0)); err != nil {
+		logger.LogE(fmt.Sprintf("task_queue_worker > batch %s: failed add callback: %v", batch.ID, err))
+	}
+}
@@ -0,0 +1,78 @@
+package taskqueueworker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckBatchFinished(t *testing.T) {
+	ctx := context.Background()
+	setupTestWorker(t)
+
+	persistent.SaveBatch(ctx, &Batch{ID: "batch", TaskName: "task-one", TotalJobs: 2})
+	persistent.SaveJob(ctx, &Job{ID: "1", TaskName: "task-one", BatchID: "batch", Status: string(statusSuccess)})
+	persistent.SaveJob(ctx, &Job{ID: "2", TaskName: "task-one", BatchID: "batch", Status: string(statusRetrying)})
+
+	checkBatchFinished(ctx, "batch")
+	res, err := GetBatch(ctx, "batch")
+	assert.NoError(t, err)
+	assert.False(t, res.IsFinished)
+	assert.Equal(t, 1, res.Detail.Success)
+	assert.Equal(t, 1, res.Detail.Retrying)
+
+	persistent.SaveJob(ctx, &Job{ID: "2", TaskName: "task-one", BatchID: "batch", Status: string(statusFailure)})
+	checkBatchFinished(ctx, "batch")
+	res, err = GetBatch(ctx, "batch")
+	assert.NoError(t, err)
+	assert.True(t, res.IsFinished)
+	assert.Equal(t, 1, res.Detail.Failure)
+}
+
+func TestBatchCallbackAfterFinishedJobDeleted(t *testing.T) {
+	ctx := context.Background()
+	var callbackArgs []byte
+	setupTestWorker(t,
+		types.WorkerHandler{Pattern: "task-one", HandlerFunc: func(ctx context.Context, message []byte) error { return nil }},
+		types.WorkerHandler{Pattern: "callback", HandlerFunc: func(ctx context.Context, message []byte) error {
+			callbackArgs = message
+			return nil
+		}},
+	)
+	worker := newTestWorker(t)
+
+	persistent.SaveBatch(ctx, &Batch{ID: "batch", TaskName: "task-one", TotalJobs: 2, CallbackTaskName: "callback", CallbackMaxRetry: 1})
+	lastJob := &Job{ID: "2", TaskName: "task-one", BatchID: "batch", MaxRetry: 1, Status: string(statusQueueing)}
+	persistent.SaveJob(ctx, lastJob)
+	queueTestJob(&Job{ID: "1", TaskName: "task-one", BatchID: "batch", MaxRetry: 1})
+	worker.dispatchJobs(1)
+	worker.wg.Wait()
+
+	// finished job deleted (like by retention sweeper) before last job in batch finished
+	assert.NoError(t, persistent.DeleteJobs(ctx, []string{"1"}))
+	queueTestJob(lastJob)
+	worker.dispatchJobs(1)
+	worker.wg.Wait()
+
+	assert.Eventually(t, func() bool {
+		worker.dispatchJobs(2)
+		worker.wg.Wait()
+		return callbackArgs != nil
+	}, time.Second, time.Millisecond)
+	var res BatchResolver
+	assert.NoError(t, json.Unmarshal(callbackArgs, &res))
+	assert.Equal(t, "batch", res.ID)
+	assert.True(t, res.IsFinished)
+	assert.Equal(t, 1, res.Detail.Success)
+
+	// wait callback job notified to worker before global state restored
+	select {
+	case <-refreshWorkerNotif:
+	case <-time.After(time.Second):
+		t.Fatal("callback job not notified to worker")
+	}
+}
@@ -0,0 +1,127 @@
+package taskqueueworker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+)
+
+// bulkJobLimit max jobs loaded from persistent in each page of bulk operation
+const bulkJobLimit = 500
+
+// StopJobs public function for stop all queueing, retrying, and scheduled jobs matched with given filter
+// (task name, status, search, and created time range), return total stopped jobs
+func StopJobs(ctx context.Context, filter Filter) (total int, err error) {
+	filter, err = bulkJobFilter(filter, statusQueueing, statusRetrying, statusScheduled)
+	if err != nil || len(filter.Status) == 0 {
+		return 0, err
+	}
+
+	// collect batch of stopped jobs, batch may be finished after all jobs stopped
+	batchIDs := make(map[string]struct{})
+	total = bulkUpdateJobs(ctx, filter, func(jobs []*Job) {
+		for _, job := range jobs {
+			job.Status = string(statusStopped)
+			if job.BatchID != "" {
+				batchIDs[job.BatchID] = struct{}{}
+			}
+		}
+		persistent.SaveJobs(ctx, jobs)
+		// stopped job in queue will be skipped because cannot be claimed
+		for _, job := range jobs {
+			stopRunningJob(job.ID, errJobStopped)
+		}
+	})
+
+	for batchID := range batchIDs {
+		checkBatchFinished(ctx, batchID)
+	}
+	if total > 0 {
+		go broadcastAllToSubscribers(context.Background())
+	}
+	return total, nil
+}
+
+// RetryJobs public function for retry all failure and stopped jobs matched with given filter
+// (task name, status, search, and created time range), return total retried jobs
+func RetryJobs(ctx context.Context, filter Filter) (total int, err error) {
+	filter, err = bulkJobFilter(filter, statusFailure, statusStopped)
+	if err != nil || len(filter.Status) == 0 {
+		return 0, err
+	}
+
+	lastJobs := make(map[string]*Job)
+	total = bulkUpdateJobs(ctx, filter, func(jobs []*Job) {
+		for _, job := range jobs {
+			job.Interval = defaultInterval
+			job.Retries = 0
+			job.Status = string(statusQueueing)
+			job.NextRetryAt = time.Time{}
+			lastJobs[job.TaskName] = job
+		}
+		persistent.SaveJobs(ctx, jobs)
+		queue.PushJobs(jobs)
+	})
+	if total == 0 {
+		return 0, nil
+	}
+
+	go func() {
+		broadcastAllToSubscribers(context.Background())
+		for taskName, job := range lastJobs {
+			registerJobToWorker(job, registeredTask[taskName].workerIndex)
+		}
+		refreshWorkerNotif <- struct{}{}
+	}()
+	return total, nil
+}
+
+// bulkJobFilter restrict status of filter to given allowed statuses (all allowed statuses if filter status is empty),
+// filter without task name is applied to all registered tasks
+func bulkJobFilter(filter Filter, allowedStatus ...JobStatusEnum) (Filter, error) {
+	taskNames := filter.TaskNameList
+	if filter.TaskName != "" {
+		taskNames = []string{filter.TaskName}
+	}
+	for _, taskName := range taskNames {
+		if _, ok := registeredTask[taskName]; !ok {
+			return filter, fmt.Errorf("task '%s' unregistered, task must one of [%s]", taskName, strings.Join(tasks, ", "))
+		}
+	}
+	if len(taskNames) == 0 {
+		filter.TaskNameList = tasks
+	}
+
+	var status []string
+	for _, allowed := range allowedStatus {
+		if len(filter.Status) == 0 || candihelper.StringInSlice(string(allowed), filter.Status) {
+			status = append(status, string(allowed))
+		}
+	}
+	filter.Status = status
+	return filter, nil
+}
+
+// bulkUpdateJobs apply update to all jobs matched with filter page by page,
+// updated jobs must not match with filter anymore (status changed)
+func bulkUpdateJobs(ctx context.Context, filter Filter, update func(jobs []*Job)) (total int) {
+	filter.Page, filter.Limit, filter.ShowAll = 1, bulkJobLimit, false
+	count := persistent.CountAllJob(ctx, filter)
+	for total < count {
+		jobs := persistent.FindAllJob(ctx, filter)
+		if len(jobs) == 0 {
+			break
+		}
+
+		page := make([]*Job, len(jobs))
+		for i := range jobs {
+			page[i] = &jobs[i]
+		}
+		update(page)
+		total += len(jobs)
+	}
+	return total
+}
@@ -0,0 +1,65 @@
+package taskqueueworker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkJobs(t *testing.T) {
+	ctx := context.Background()
+	setupTestWorker(t, types.WorkerHandler{Pattern: "task-one"})
+
+	_, err := AddJobs("task-one", 1, [][]byte{[]byte("1")}, AddJobOptionUniqueKey("key", 0))
+	assert.Error(t, err)
+	_, err = AddJobs("task-two", 1, [][]byte{[]byte("1")})
+	assert.Error(t, err)
+
+	jobIDs, err := AddJobs("task-one", 1, [][]byte{[]byte("1"), []byte("2"), []byte("3")})
+	assert.NoError(t, err)
+	assert.Len(t, jobIDs, 3)
+	<-refreshWorkerNotif
+	assert.Equal(t, jobIDs[0], queue.NextJob("task-one"))
+
+	// dashboard formatted value must not saved to persistent
+	defaultOption.JaegerTracingDashboard = "jaeger.host"
+	defer func() { defaultOption.JaegerTracingDashboard = "" }()
+	tracedJob, _ := persistent.FindJobByID(ctx, jobIDs[0])
+	tracedJob.TraceID = "trace-id"
+	persistent.SaveJob(ctx, tracedJob)
+
+	_, err = StopJobs(ctx, Filter{TaskName: "task-two"})
+	assert.Error(t, err)
+	total, err := StopJobs(ctx, Filter{Status: []string{string(statusSuccess)}})
+	assert.NoError(t, err)
+	assert.Zero(t, total)
+
+	search := "2"
+	total, err = StopJobs(ctx, Filter{TaskName: "task-one", Search: &search})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	total, err = StopJobs(ctx, Filter{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, 3, persistent.CountAllJob(ctx, Filter{Status: []string{string(statusStopped)}}))
+	tracedJob, _ = persistent.FindJobByID(ctx, jobIDs[0])
+	assert.Equal(t, "trace-id", tracedJob.TraceID)
+
+	createdAfter := time.Now().Add(time.Minute)
+	total, err = RetryJobs(ctx, Filter{TaskName: "task-one", CreatedAfter: &createdAfter})
+	assert.NoError(t, err)
+	assert.Zero(t, total)
+
+	queue.Clear("task-one")
+	total, err = RetryJobs(ctx, Filter{TaskName: "task-one", Status: []string{string(statusStopped), string(statusQueueing)}})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	<-refreshWorkerNotif
+	assert.Equal(t, 3, persistent.CountAllJob(ctx, Filter{Status: []string{string(statusQueueing)}}))
+	assert.NotEmpty(t, queue.NextJob("task-one"))
+	tracedJob, _ = persistent.FindJobByID(ctx, jobIDs[0])
+	assert.Equal(t, "trace-id", tracedJob.TraceID)
+}
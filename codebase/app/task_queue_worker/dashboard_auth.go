@@ -0,0 +1,86 @@
+package taskqueueworker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/middleware"
+	gqlerrors "github.com/golangid/graphql-go/errors"
+	"github.com/golangid/graphql-go/trace"
+)
+
+// DashboardAuth config for authentication and authorization of dashboard GraphQL API using service middleware,
+// read-only role for all query and subscription, operator role for all mutation. Subscription client send
+// authorization in connection_init payload (example: {"Authorization": "Bearer <token>"})
+type DashboardAuth struct {
+	middleware.DashboardAuth
+	// MutationPermissionCodes override ACL permission code for specific mutation (key is mutation field name, example: stop_all_job)
+	MutationPermissionCodes map[string]string
+}
+
+// middlewares get all middleware for given graphql root type and field name, dashboard not protected if auth is not set
+func (d *DashboardAuth) middlewares(typeName, fieldName string) []types.MiddlewareFunc {
+	if d == nil {
+		return nil
+	}
+
+	permissionCode := d.PermissionCode(typeName == "Mutation")
+	if code, ok := d.MutationPermissionCodes[fieldName]; ok && typeName == "Mutation" {
+		permissionCode = code
+	}
+	return d.GraphQLMiddlewares(permissionCode)
+}
+
+// authorize check given graphql root field, return error if unauthorized or forbidden
+func (d *DashboardAuth) authorize(ctx context.Context, typeName, fieldName string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(error); ok {
+				err = e
+				return
+			}
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	for _, mw := range d.middlewares(typeName, fieldName) {
+		ctx = mw(ctx)
+	}
+	return nil
+}
+
+// dashboardTracer intercept graphql root field for check dashboard auth
+type dashboardTracer struct {
+	trace.NoopTracer
+	auth *DashboardAuth
+}
+
+func (t *dashboardTracer) TraceField(ctx context.Context, label, typeName, fieldName string, trivial bool, args map[string]interface{}) (context.Context, trace.TraceFieldFinishFunc) {
+	if typeName == "Query" || typeName == "Mutation" {
+		// middleware panic with graphql error when unauthorized, recovered by graphql executor
+		for _, mw := range t.auth.middlewares(typeName, fieldName) {
+			ctx = mw(ctx)
+		}
+	}
+	return ctx, func(data []byte, err *gqlerrors.QueryError) {}
+}
+
+// httpMiddleware wrap dashboard static and voyager handler, browser will prompt credential when using basic auth.
+// In bearer auth, dashboard must be served behind proxy which set authorization header
+func (d *DashboardAuth) httpMiddleware(next http.Handler) http.Handler {
+	if d == nil {
+		return next
+	}
+	return d.HTTPMiddleware(next, "")
+}
+
+// withHTTPHeader set request header to context, required by graphql middleware
+func withHTTPHeader(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx := candishared.SetToContext(req.Context(), candishared.ContextKeyHTTPHeader, req.Header)
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
@@ -0,0 +1,69 @@
+package taskqueueworker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/middleware"
+	mockinterfaces "github.com/golangid/candi/mocks/codebase/interfaces"
+	gqlerrors "github.com/golangid/graphql-go/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDashboardAuth(t *testing.T) {
+	ctx := context.Background()
+	var nilAuth *DashboardAuth
+	assert.NoError(t, nilAuth.authorize(ctx, "Mutation", "stop_all_job"))
+
+	var aclCodes []string
+	mw := &mockinterfaces.Middleware{}
+	mw.On("GraphQLBasicAuth", mock.Anything).Return(ctx)
+	mw.On("GraphQLBearerAuth", mock.Anything).Return(ctx)
+	mw.On("GraphQLPermissionACL", mock.Anything).Return(types.MiddlewareFunc(func(ctx context.Context) context.Context {
+		panic(&gqlerrors.QueryError{Message: "forbidden"})
+	})).Run(func(args mock.Arguments) {
+		aclCodes = append(aclCodes, args.String(0))
+	})
+
+	auth := &DashboardAuth{DashboardAuth: middleware.DashboardAuth{Middleware: mw, AuthType: middleware.Basic, OperatorPermissionCode: "operator"}}
+	assert.Len(t, auth.middlewares("Mutation", "stop_all_job"), 1)
+	assert.NoError(t, auth.authorize(ctx, "Mutation", "stop_all_job"))
+
+	auth = &DashboardAuth{
+		DashboardAuth:           middleware.DashboardAuth{Middleware: mw, AuthType: middleware.Bearer, OperatorPermissionCode: "operator"},
+		MutationPermissionCodes: map[string]string{"clear_all_client_subscriber": "admin"},
+	}
+	assert.Len(t, auth.middlewares("Query", "dashboard"), 1)
+	assert.NoError(t, auth.authorize(ctx, "Subscription", "listen_task"))
+	assert.EqualError(t, auth.authorize(ctx, "Mutation", "stop_all_job"), "graphql: forbidden")
+	assert.Error(t, auth.authorize(ctx, "Mutation", "clear_all_client_subscriber"))
+	assert.Equal(t, []string{"operator", "admin"}, aclCodes)
+
+	auth.ReadPermissionCode = "read"
+	assert.Len(t, auth.middlewares("Subscription", "listen_task"), 2)
+
+	assert.Panics(t, func() {
+		SetDashboardAuth(DashboardAuth{DashboardAuth: middleware.DashboardAuth{AuthType: middleware.Basic}})
+	})
+}
+
+func TestDashboardAuthHTTPMiddleware(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	var nilAuth *DashboardAuth
+	assert.NotNil(t, nilAuth.httpMiddleware(handler))
+
+	// static and voyager handler also protected in bearer auth
+	unauthorized := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusUnauthorized) })
+	mw := &mockinterfaces.Middleware{}
+	mw.On("HTTPBearerAuth", mock.Anything).Return(unauthorized)
+	auth := &DashboardAuth{DashboardAuth: middleware.DashboardAuth{Middleware: mw, AuthType: middleware.Bearer}}
+
+	rec := httptest.NewRecorder()
+	auth.httpMiddleware(handler).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/voyager", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	mw.AssertCalled(t, "HTTPBearerAuth", mock.Anything)
+}
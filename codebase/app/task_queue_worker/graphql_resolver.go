@@ -26,13 +26,19 @@ func serveGraphQLAPI(wrk *taskQueueWorker) {
 		graphql.UseStringDescriptions(),
 		graphql.UseFieldResolvers(),
 	}
+	auth := defaultOption.DashboardAuth
+	if auth != nil {
+		schemaOpts = append(schemaOpts, graphql.Tracer(&dashboardTracer{auth: auth}))
+	}
 	schema := graphql.MustParseSchema(schema, &rootResolver{worker: wrk}, schemaOpts...)
 
 	mux := http.NewServeMux()
-	mux.Handle("/", http.StripPrefix("/", http.FileServer(external.Dashboard)))
-	mux.Handle("/task", http.StripPrefix("/task", http.FileServer(external.Dashboard)))
-	mux.HandleFunc("/graphql", ws.NewHandlerFunc(schema, &relay.Handler{Schema: schema}))
-	mux.HandleFunc("/voyager", func(rw http.ResponseWriter, r *http.Request) { rw.Write([]byte(static.VoyagerAsset)) })
+	mux.Handle("/", auth.httpMiddleware(http.StripPrefix("/", http.FileServer(external.Dashboard))))
+	mux.Handle("/task", auth.httpMiddleware(http.StripPrefix("/task", http.FileServer(external.Dashboard))))
+	mux.HandleFunc("/graphql", ws.NewHandlerFunc(schema, withHTTPHeader(&relay.Handler{Schema: schema})))
+	mux.Handle("/voyager", auth.httpMiddleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(static.VoyagerAsset))
+	})))
 
 	httpEngine := new(http.Server)
 	httpEngine.Addr = fmt.Sprintf(":%d", env.BaseEnv().TaskQueueDashboardPort)
@@ -64,12 +70,79 @@ func (r *rootResolver) Tagline(ctx context.Context) (res TaglineResolver) {
 	return
 }
 
+func (r *rootResolver) GetJobDetail(ctx context.Context, input struct {
+	JobID string
+}) (*Job, error) {
+
+	job, err := persistent.FindJobByID(ctx, input.JobID)
+	if err != nil {
+		return nil, err
+	}
+	job.updateValue()
+	return job, nil
+}
+
+func (r *rootResolver) GetWorkflow(ctx context.Context, input struct {
+	JobID string
+}) (res WorkflowResolver, err error) {
+
+	job, err := persistent.FindJobByID(ctx, input.JobID)
+	if err != nil {
+		return res, err
+	}
+	if job.WorkflowID != "" {
+		if job, err = persistent.FindJobByID(ctx, job.WorkflowID); err != nil {
+			return res, err
+		}
+	}
+	return getWorkflowTree(ctx, job), nil
+}
+
+func (r *rootResolver) GetBatch(ctx context.Context, input struct {
+	BatchID string
+}) (BatchResolver, error) {
+	return GetBatch(ctx, input.BatchID)
+}
+
 func (r *rootResolver) AddJob(ctx context.Context, input struct {
-	TaskName string
-	MaxRetry int32
-	Args     string
+	TaskName     string
+	MaxRetry     int32
+	Args         string
+	Priority     *int32
+	UniqueKey    *string
+	UniqueWindow *string
+	RunAt        *string
+	Timeout      *string
 }) (string, error) {
-	return "ok", AddJob(input.TaskName, int(input.MaxRetry), []byte(input.Args))
+	var opts []AddJobOptionFunc
+	if input.Priority != nil {
+		opts = append(opts, AddJobOptionPriority(int(*input.Priority)))
+	}
+	if input.UniqueKey != nil && *input.UniqueKey != "" {
+		var window time.Duration
+		if input.UniqueWindow != nil && *input.UniqueWindow != "" {
+			var err error
+			if window, err = time.ParseDuration(*input.UniqueWindow); err != nil {
+				return "", fmt.Errorf("invalid unique window: %v", err)
+			}
+		}
+		opts = append(opts, AddJobOptionUniqueKey(*input.UniqueKey, window))
+	}
+	if input.RunAt != nil && *input.RunAt != "" {
+		runAt, err := time.Parse(time.RFC3339, *input.RunAt)
+		if err != nil {
+			return "", fmt.Errorf("invalid run at: %v", err)
+		}
+		opts = append(opts, AddJobOptionRunAt(runAt))
+	}
+	if input.Timeout != nil && *input.Timeout != "" {
+		timeout, err := time.ParseDuration(*input.Timeout)
+		if err != nil {
+			return "", fmt.Errorf("invalid timeout: %v", err)
+		}
+		opts = append(opts, AddJobOptionTimeout(timeout))
+	}
+	return AddJobWithOption(input.TaskName, int(input.MaxRetry), []byte(input.Args), opts...)
 }
 
 func (r *rootResolver) StopJob(ctx context.Context, input struct {
@@ -83,6 +156,8 @@ func (r *rootResolver) StopJob(ctx context.Context, input struct {
 
 	job.Status = string(statusStopped)
 	persistent.SaveJob(ctx, job)
+	stopRunningJob(job.ID, errJobStopped)
+	checkBatchFinished(ctx, job.BatchID)
 	broadcastAllToSubscribers(r.worker.ctx)
 
 	return "Success stop job " + input.JobID, nil
@@ -96,8 +171,22 @@ func (r *rootResolver) StopAllJob(ctx context.Context, input struct {
 		return "", fmt.Errorf("task '%s' unregistered, task must one of [%s]", input.TaskName, strings.Join(tasks, ", "))
 	}
 
+	// collect batch of stopped jobs, batch may be finished after all jobs stopped
+	batchIDs := make(map[string]struct{})
+	for _, job := range persistent.FindAllJob(ctx, Filter{
+		TaskName: input.TaskName, ShowAll: true,
+		Status: []string{string(statusQueueing), string(statusRetrying), string(statusScheduled)},
+	}) {
+		if job.BatchID != "" {
+			batchIDs[job.BatchID] = struct{}{}
+		}
+	}
+
 	queue.Clear(input.TaskName)
-	persistent.UpdateAllStatus(ctx, input.TaskName, []JobStatusEnum{statusQueueing, statusRetrying}, statusStopped)
+	persistent.UpdateAllStatus(ctx, input.TaskName, []JobStatusEnum{statusQueueing, statusRetrying, statusScheduled}, statusStopped)
+	for batchID := range batchIDs {
+		checkBatchFinished(ctx, batchID)
+	}
 	broadcastAllToSubscribers(r.worker.ctx)
 
 	return "Success stop all job in task " + input.TaskName, nil
@@ -118,8 +207,9 @@ func (r *rootResolver) RetryJob(ctx context.Context, input struct {
 			job.Retries = 0
 		}
 		job.Status = string(statusQueueing)
-		queue.PushJob(job)
+		// status must be saved before pushed to queue, so that job can be claimed when popped
 		persistent.SaveJob(r.worker.ctx, job)
+		queue.PushJob(job)
 		broadcastAllToSubscribers(r.worker.ctx)
 		registerJobToWorker(job, task.workerIndex)
 		refreshWorkerNotif <- struct{}{}
@@ -142,31 +232,67 @@ func (r *rootResolver) RetryAllJob(ctx context.Context, input struct {
 	TaskName string
 }) (string, error) {
 
-	pageNumber := 1
-	filter := Filter{Limit: 10, Status: []string{string(statusFailure), string(statusStopped)}, TaskName: input.TaskName}
-	count := persistent.CountAllJob(ctx, filter)
-	totalPages := int(math.Ceil(float64(count) / float64(filter.Limit)))
-	for pageNumber <= totalPages {
-		filter.Page = pageNumber
-		jobs := persistent.FindAllJob(ctx, filter)
-		for _, job := range jobs {
-			job.Interval = defaultInterval
-			job.Retries = 0
-			task := registeredTask[job.TaskName]
-			job.Status = string(statusQueueing)
-			queue.PushJob(&job)
-			registerJobToWorker(&job, task.workerIndex)
+	if _, err := RetryJobs(ctx, Filter{TaskName: input.TaskName}); err != nil {
+		return "Failed", err
+	}
+
+	return "Success retry all failure job in task " + input.TaskName, nil
+}
+
+type bulkJobFilterInput struct {
+	TaskName                    string
+	Status                      *[]string
+	Search                      *string
+	CreatedAfter, CreatedBefore *string
+}
+
+func (input bulkJobFilterInput) toFilter() (filter Filter, err error) {
+	filter.TaskName = input.TaskName
+	filter.Search = input.Search
+	if input.Status != nil {
+		filter.Status = *input.Status
+	}
+	if input.CreatedAfter != nil && *input.CreatedAfter != "" {
+		createdAfter, err := time.Parse(time.RFC3339, *input.CreatedAfter)
+		if err != nil {
+			return filter, fmt.Errorf("invalid created after: %v", err)
 		}
-		pageNumber++
+		filter.CreatedAfter = &createdAfter
 	}
+	if input.CreatedBefore != nil && *input.CreatedBefore != "" {
+		createdBefore, err := time.Parse(time.RFC3339, *input.CreatedBefore)
+		if err != nil {
+			return filter, fmt.Errorf("invalid created before: %v", err)
+		}
+		filter.CreatedBefore = &createdBefore
+	}
+	return filter, nil
+}
 
-	persistent.UpdateAllStatus(ctx, input.TaskName, []JobStatusEnum{statusFailure, statusStopped}, statusQueueing)
-	go func() {
-		broadcastAllToSubscribers(r.worker.ctx)
-		refreshWorkerNotif <- struct{}{}
-	}()
+func (r *rootResolver) BulkStopJob(ctx context.Context, input bulkJobFilterInput) (string, error) {
+	filter, err := input.toFilter()
+	if err != nil {
+		return "", err
+	}
 
-	return "Success retry all failure job in task " + input.TaskName, nil
+	total, err := StopJobs(ctx, filter)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Success stop %d job in task %s", total, input.TaskName), nil
+}
+
+func (r *rootResolver) BulkRetryJob(ctx context.Context, input bulkJobFilterInput) (string, error) {
+	filter, err := input.toFilter()
+	if err != nil {
+		return "", err
+	}
+
+	total, err := RetryJobs(ctx, filter)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Success retry %d job in task %s", total, input.TaskName), nil
 }
 
 func (r *rootResolver) ClearAllClientSubscriber(ctx context.Context) (string, error) {
@@ -181,7 +307,90 @@ func (r *rootResolver) ClearAllClientSubscriber(ctx context.Context) (string, er
 	return "Success clear all client subscriber", nil
 }
 
+func (r *rootResolver) GetAllRecurringJob(ctx context.Context, input struct {
+	TaskName *string
+}) (res []RecurringJob) {
+
+	var taskName string
+	if input.TaskName != nil {
+		taskName = *input.TaskName
+	}
+	res = persistent.FindAllRecurringJob(ctx, taskName)
+	for i := range res {
+		res[i].updateValue()
+	}
+	return
+}
+
+func (r *rootResolver) SaveRecurringJob(ctx context.Context, input struct {
+	ID       string
+	TaskName string
+	MaxRetry int32
+	Args     string
+	Schedule string
+}) (string, error) {
+
+	if err := AddRecurringJob(input.ID, input.TaskName, int(input.MaxRetry), []byte(input.Args), input.Schedule); err != nil {
+		return "", err
+	}
+	return "Success save recurring job " + input.ID, nil
+}
+
+func (r *rootResolver) PauseRecurringJob(ctx context.Context, input struct {
+	ID string
+}) (string, error) {
+
+	if err := PauseRecurringJob(input.ID); err != nil {
+		return "", err
+	}
+	return "Success pause recurring job " + input.ID, nil
+}
+
+func (r *rootResolver) ResumeRecurringJob(ctx context.Context, input struct {
+	ID string
+}) (string, error) {
+
+	if err := ResumeRecurringJob(input.ID); err != nil {
+		return "", err
+	}
+	return "Success resume recurring job " + input.ID, nil
+}
+
+func (r *rootResolver) DeleteRecurringJob(ctx context.Context, input struct {
+	ID string
+}) (string, error) {
+
+	if err := DeleteRecurringJob(input.ID); err != nil {
+		return "", err
+	}
+	return "Success delete recurring job " + input.ID, nil
+}
+
+func (r *rootResolver) PauseTask(ctx context.Context, input struct {
+	TaskName string
+}) (string, error) {
+
+	if err := PauseTask(input.TaskName); err != nil {
+		return "", err
+	}
+	return "Success pause task " + input.TaskName, nil
+}
+
+func (r *rootResolver) ResumeTask(ctx context.Context, input struct {
+	TaskName string
+}) (string, error) {
+
+	if err := ResumeTask(input.TaskName); err != nil {
+		return "", err
+	}
+	return "Success resume task " + input.TaskName, nil
+}
+
 func (r *rootResolver) ListenTask(ctx context.Context) (<-chan TaskListResolver, error) {
+	if err := defaultOption.DashboardAuth.authorize(ctx, "Subscription", "listen_task"); err != nil {
+		return nil, err
+	}
+
 	output := make(chan TaskListResolver)
 
 	httpHeader := candishared.GetValueFromContext(ctx, candishared.ContextKeyHTTPHeader).(http.Header)
@@ -229,6 +438,9 @@ func (r *rootResolver) ListenTaskJobDetail(ctx context.Context, input struct {
 	Search      *string
 	Status      []string
 }) (<-chan JobListResolver, error) {
+	if err := defaultOption.DashboardAuth.authorize(ctx, "Subscription", "listen_task_job_detail"); err != nil {
+		return nil, err
+	}
 
 	output := make(chan JobListResolver)
 
@@ -254,6 +466,9 @@ func (r *rootResolver) ListenTaskJobDetail(ctx context.Context, input struct {
 
 	go func() {
 		jobs := persistent.FindAllJob(r.worker.ctx, filter)
+		for i := range jobs {
+			jobs[i].updateValue()
+		}
 		var meta MetaJobList
 		filter.TaskNameList = []string{filter.TaskName}
 		counterAll := persistent.AggregateAllTaskJob(r.worker.ctx, filter)
@@ -263,6 +478,7 @@ func (r *rootResolver) ListenTaskJobDetail(ctx context.Context, input struct {
 			meta.Detail.Success = counterAll[0].Detail.Success
 			meta.Detail.Queueing = counterAll[0].Detail.Queueing
 			meta.Detail.Stopped = counterAll[0].Detail.Stopped
+			meta.Detail.Scheduled = counterAll[0].Detail.Scheduled
 			meta.TotalRecords = counterAll[0].TotalJobs
 		}
 		meta.Page, meta.Limit = filter.Page, filter.Limit
@@ -8,16 +8,28 @@ const schema = `schema {
 
 type Query {
 	tagline(): TaglineType!
+	get_job_detail(job_id: String!): JobResolver!
+	get_workflow(job_id: String!): WorkflowResolver!
+	get_batch(batch_id: String!): BatchResolver!
+	get_all_recurring_job(task_name: String): [RecurringJobResolver!]!
 }
 
 type Mutation {
-	add_job(task_name: String!, max_retry: Int!, args: String!): String!
+	add_job(task_name: String!, max_retry: Int!, args: String!, priority: Int, unique_key: String, unique_window: String, run_at: String, timeout: String): String!
 	stop_job(job_id: String!): String!
 	stop_all_job(task_name: String!): String!
 	retry_job(job_id: String!): String!
 	clean_job(task_name: String!): String!
 	retry_all_job(task_name: String!): String!
+	bulk_stop_job(task_name: String!, status: [String!], search: String, created_after: String, created_before: String): String!
+	bulk_retry_job(task_name: String!, status: [String!], search: String, created_after: String, created_before: String): String!
 	clear_all_client_subscriber(): String!
+	save_recurring_job(id: String!, task_name: String!, max_retry: Int!, args: String!, schedule: String!): String!
+	pause_recurring_job(id: String!): String!
+	resume_recurring_job(id: String!): String!
+	delete_recurring_job(id: String!): String!
+	pause_task(task_name: String!): String!
+	resume_task(task_name: String!): String!
 }
 
 type Subscription {
@@ -62,6 +74,9 @@ type MemstatsResolver {
 type TaskResolver {
 	name: String!
 	total_jobs: Int!
+	max_concurrency: Int!
+	running_jobs: Int!
+	is_paused: Boolean!
 	detail: TaskDetailResolver!
 }
 
@@ -76,6 +91,7 @@ type TaskDetailResolver {
 	success: Int!
 	queueing: Int!
 	stopped: Int!
+	scheduled: Int!
 }
 
 type JobListResolver {
@@ -96,4 +112,57 @@ type JobResolver {
 	created_at: String!
 	finished_at: String!
 	next_retry_at: String!
+	run_at: String!
+	priority: Int!
+	unique_key: String!
+	worker_id: String!
+	timeout: String!
+	histories: [JobHistoryResolver!]!
+	progress: Int!
+	progress_message: String!
+	workflow_id: String!
+	parent_id: String!
+	child_ids: [String!]!
+	result: String!
+	batch_id: String!
+	recurring_job_id: String!
+}
+
+type RecurringJobResolver {
+	id: String!
+	task_name: String!
+	arguments: String!
+	max_retry: Int!
+	schedule: String!
+	is_paused: Boolean!
+	next_run_at: String!
+	last_run_at: String!
+	last_job_id: String!
+	created_at: String!
+	updated_at: String!
+}
+
+type BatchResolver {
+	id: String!
+	task_name: String!
+	total_jobs: Int!
+	is_finished: Boolean!
+	created_at: String!
+	finished_at: String!
+	detail: TaskDetailResolver!
+}
+
+type WorkflowResolver {
+	job: JobResolver!
+	children: [WorkflowResolver!]!
+	next_steps: [String!]!
+}
+
+type JobHistoryResolver {
+	retries: Int!
+	start_at: String!
+	end_at: String!
+	status: String!
+	error: String!
+	trace_id: String!
 }`
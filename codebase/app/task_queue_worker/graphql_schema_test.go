@@ -0,0 +1,13 @@
+package taskqueueworker
+
+import (
+	"testing"
+
+	"github.com/golangid/graphql-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraphQLSchema(t *testing.T) {
+	_, err := graphql.ParseSchema(schema, &rootResolver{}, graphql.UseStringDescriptions(), graphql.UseFieldResolvers())
+	assert.NoError(t, err)
+}
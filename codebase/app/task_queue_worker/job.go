@@ -17,75 +17,215 @@ import (
 
 const (
 	defaultInterval = "1s"
+
+	// PriorityLow job priority
+	PriorityLow = -1
+	// PriorityNormal job priority, default priority
+	PriorityNormal = 0
+	// PriorityHigh job priority
+	PriorityHigh = 1
+
+	// MinPriority lowest allowed job priority
+	MinPriority = -100
+	// MaxPriority highest allowed job priority
+	MaxPriority = 100
 )
 
 type (
 	// Job model
 	Job struct {
-		ID          string    `bson:"_id" json:"_id"`
-		TaskName    string    `bson:"task_name" json:"task_name"`
-		Arguments   string    `bson:"arguments" json:"arguments"`
-		Retries     int       `bson:"retries" json:"retries"`
-		MaxRetry    int       `bson:"max_retry" json:"max_retry"`
-		Interval    string    `bson:"interval" json:"interval"`
-		CreatedAt   time.Time `bson:"created_at" json:"created_at"`
-		FinishedAt  time.Time `bson:"finished_at" json:"finished_at"`
-		Status      string    `bson:"status" json:"status"`
-		Error       string    `bson:"error" json:"error"`
-		TraceID     string    `bson:"traceId" json:"traceId"`
-		NextRetryAt string    `bson:"-" json:"-"`
-	}
-
-	errorHistory struct {
-		Error   string `json:"error"`
-		TraceID string `json:"traceID"`
+		ID          string       `bson:"_id" json:"_id"`
+		TaskName    string       `bson:"task_name" json:"task_name"`
+		Arguments   string       `bson:"arguments" json:"arguments"`
+		Retries     int          `bson:"retries" json:"retries"`
+		MaxRetry    int          `bson:"max_retry" json:"max_retry"`
+		Interval    string       `bson:"interval" json:"interval"`
+		CreatedAt   time.Time    `bson:"created_at" json:"created_at"`
+		FinishedAt  time.Time    `bson:"finished_at" json:"finished_at"`
+		Status      string       `bson:"status" json:"status"`
+		Error       string       `bson:"error" json:"error"`
+		TraceID     string       `bson:"traceId" json:"traceId"`
+		RunAt       time.Time    `bson:"run_at" json:"run_at"`
+		Priority    int          `bson:"priority" json:"priority"`
+		UniqueKey   string       `bson:"unique_key" json:"unique_key"`
+		WorkerID    string       `bson:"worker_id" json:"worker_id"`
+		LeaseUntil  time.Time    `bson:"lease_until" json:"lease_until"`
+		NextRetryAt time.Time    `bson:"next_retry_at" json:"next_retry_at"`
+		Timeout     string       `bson:"timeout" json:"timeout"`
+		Histories   []JobHistory `bson:"histories" json:"histories"`
+
+		// Progress percentage of running job (0-100) reported by handler, see ReportJobProgress
+		Progress        int    `bson:"progress" json:"progress"`
+		ProgressMessage string `bson:"progress_message" json:"progress_message"`
+
+		// workflow fields, parent & child jobs is linked by workflow steps
+		WorkflowID string         `bson:"workflow_id" json:"workflow_id"`
+		ParentID   string         `bson:"parent_id" json:"parent_id"`
+		ChildIDs   []string       `bson:"child_ids" json:"child_ids"`
+		NextSteps  []WorkflowStep `bson:"next_steps" json:"next_steps"`
+		Result     string         `bson:"result" json:"result"`
+
+		BatchID        string `bson:"batch_id" json:"batch_id"`
+		RecurringJobID string `bson:"recurring_job_id" json:"recurring_job_id"`
+	}
+
+	// JobHistory model, record of each job execution attempt
+	JobHistory struct {
+		Retries int       `bson:"retries" json:"retries"`
+		StartAt time.Time `bson:"start_at" json:"start_at"`
+		EndAt   time.Time `bson:"end_at" json:"end_at"`
+		Status  string    `bson:"status" json:"status"`
+		Error   string    `bson:"error" json:"error"`
+		TraceID string    `bson:"trace_id" json:"trace_id"`
 	}
 )
 
 // AddJob public function for add new job in same runtime
 func AddJob(taskName string, maxRetry int, args []byte) (err error) {
+	_, err = AddJobWithOption(taskName, maxRetry, args)
+	return
+}
+
+// AddJobWithOption public function for add new job in same runtime with additional option (example: scheduled job)
+func AddJobWithOption(taskName string, maxRetry int, args []byte, opts ...AddJobOptionFunc) (jobID string, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			err = fmt.Errorf("%v", r)
 		}
 	}()
 
-	task, ok := registeredTask[taskName]
-	if !ok {
-		var tasks []string
-		for taskName := range registeredTask {
-			tasks = append(tasks, taskName)
-		}
-		return fmt.Errorf("task '%s' unregistered, task must one of [%s]", taskName, strings.Join(tasks, ", "))
+	var opt addJobOption
+	for _, o := range opts {
+		o(&opt)
 	}
 
-	if maxRetry <= 0 {
-		return errors.New("Max retry must greater than 0")
+	workerIndex, err := validateJob(taskName, maxRetry, opt)
+	if err != nil {
+		return "", err
+	}
+	if err := validateJobArgs(taskName, args); err != nil {
+		return "", err
 	}
 
-	var newJob Job
-	newJob.ID = uuid.New().String()
-	newJob.TaskName = taskName
-	newJob.Arguments = string(args)
-	newJob.MaxRetry = maxRetry
-	newJob.Interval = defaultInterval
-	newJob.Status = string(statusQueueing)
-	newJob.CreatedAt = time.Now()
+	ctx := context.Background()
+	newJob := createJob(taskName, maxRetry, args, opt)
+	if opt.uniqueKey != "" {
+		var createdAfter time.Time
+		if opt.uniqueWindow > 0 {
+			createdAfter = newJob.CreatedAt.Add(-opt.uniqueWindow)
+		}
+		existingJob, err := persistent.SaveUniqueJob(ctx, newJob, createdAfter)
+		if err != nil {
+			return "", err
+		}
+		if existingJob != nil {
+			return existingJob.ID, nil
+		}
+	} else {
+		persistent.SaveJob(ctx, newJob)
+	}
 
 	go func(job *Job, workerIndex int) {
 		ctx := context.Background()
-		queue.PushJob(job)
-		persistent.SaveJob(ctx, job)
 		broadcastAllToSubscribers(ctx)
+		if job.Status == string(statusScheduled) {
+			return
+		}
+		queue.PushJob(job)
 		registerJobToWorker(job, workerIndex)
 		refreshWorkerNotif <- struct{}{}
-	}(&newJob, task.workerIndex)
+	}(newJob, workerIndex)
 
-	return nil
+	return newJob.ID, nil
+}
+
+// AddJobs public function for add multiple jobs of given task at once, all jobs saved to persistent
+// and pushed to queue in one round trip (unique key option is not supported)
+func AddJobs(taskName string, maxRetry int, argsList [][]byte, opts ...AddJobOptionFunc) (jobIDs []string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	var opt addJobOption
+	for _, o := range opts {
+		o(&opt)
+	}
+	if opt.uniqueKey != "" {
+		return nil, errors.New("Unique key option is not supported in bulk add jobs")
+	}
+
+	workerIndex, err := validateJob(taskName, maxRetry, opt)
+	if err != nil {
+		return nil, err
+	}
+	for i, args := range argsList {
+		if err := validateJobArgs(taskName, args); err != nil {
+			return nil, fmt.Errorf("job %d: %v", i, err)
+		}
+	}
+
+	jobs := make([]*Job, len(argsList))
+	jobIDs = make([]string, len(argsList))
+	for i, args := range argsList {
+		jobs[i] = createJob(taskName, maxRetry, args, opt)
+		jobIDs[i] = jobs[i].ID
+	}
+	saveAndPushJobs(context.Background(), jobs, workerIndex)
+	return jobIDs, nil
+}
+
+// saveAndPushJobs save all jobs and push non scheduled jobs to queue, subscribers is notified once
+func saveAndPushJobs(ctx context.Context, jobs []*Job, workerIndex int) {
+	if len(jobs) == 0 {
+		return
+	}
+
+	persistent.SaveJobs(ctx, jobs)
+	var queuedJobs []*Job
+	for _, job := range jobs {
+		if job.Status != string(statusScheduled) {
+			queuedJobs = append(queuedJobs, job)
+		}
+	}
+	queue.PushJobs(queuedJobs)
+
+	go func() {
+		broadcastAllToSubscribers(context.Background())
+		if len(queuedJobs) == 0 {
+			return
+		}
+		registerJobToWorker(queuedJobs[0], workerIndex)
+		refreshWorkerNotif <- struct{}{}
+	}()
 }
 
-// AddJobViaHTTPRequest public function for add new job via http request
-func AddJobViaHTTPRequest(ctx context.Context, workerHost string, taskName string, maxRetry int, args []byte) error {
+// AddJobViaHTTPRequest public function for add new job via http request, return ID of new job
+// (or ID of existing job if job with same unique key has been added)
+func AddJobViaHTTPRequest(ctx context.Context, workerHost string, taskName string, maxRetry int, args []byte, opts ...AddJobOptionFunc) (jobID string, err error) {
+	var opt addJobOption
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	variables := map[string]interface{}{
+		"taskName": taskName,
+		"maxRetry": maxRetry,
+		"args":     string(args),
+		"priority": opt.priority,
+	}
+	if opt.uniqueKey != "" {
+		variables["uniqueKey"] = opt.uniqueKey
+		variables["uniqueWindow"] = opt.uniqueWindow.String()
+	}
+	if opt.timeout > 0 {
+		variables["timeout"] = opt.timeout.String()
+	}
+	if !opt.runAt.IsZero() {
+		variables["runAt"] = opt.runAt.Format(time.RFC3339)
+	}
+
 	httpReq := candiutils.NewHTTPRequest(
 		candiutils.HTTPRequestSetBreakerName("task_queue_worker_add_job"),
 	)
@@ -95,16 +235,14 @@ func AddJobViaHTTPRequest(ctx context.Context, workerHost string, taskName strin
 	}
 	reqBody := map[string]interface{}{
 		"operationName": "AddJob",
-		"variables": map[string]interface{}{
-			"taskName": taskName,
-			"maxRetry": maxRetry,
-			"args":     string(args),
-		},
-		"query": "mutation AddJob($taskName: String!, $maxRetry: Int!, $args: String!) {\n  add_job(task_name: $taskName, max_retry: $maxRetry, args: $args)\n}\n",
+		"variables":     variables,
+		"query": `mutation AddJob($taskName: String!, $maxRetry: Int!, $args: String!, $priority: Int, $uniqueKey: String, $uniqueWindow: String, $runAt: String, $timeout: String) {
+  add_job(task_name: $taskName, max_retry: $maxRetry, args: $args, priority: $priority, unique_key: $uniqueKey, unique_window: $uniqueWindow, run_at: $runAt, timeout: $timeout)
+}`,
 	}
 	respBody, _, err := httpReq.Do(ctx, http.MethodPost, workerHost+"/graphql", candihelper.ToBytes(reqBody), header)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	var respPayload struct {
@@ -117,13 +255,113 @@ func AddJobViaHTTPRequest(ctx context.Context, workerHost string, taskName strin
 	}
 	json.Unmarshal(respBody, &respPayload)
 	if len(respPayload.Errors) > 0 {
-		return errors.New(respPayload.Errors[0].Message)
+		return "", errors.New(respPayload.Errors[0].Message)
+	}
+	return respPayload.Data.AddJob, nil
+}
+
+// updateValue format job value for display in dashboard, updated job must not saved to persistent
+func (job *Job) updateValue() {
+	if job.Status == string(statusSuccess) {
+		job.Error = ""
+	}
+	if job.TraceID != "" && defaultOption.JaegerTracingDashboard != "" {
+		job.TraceID = fmt.Sprintf("%s/trace/%s", defaultOption.JaegerTracingDashboard, job.TraceID)
+	}
+	job.CreatedAt = job.CreatedAt.In(candihelper.AsiaJakartaLocalTime)
+	job.FinishedAt = job.FinishedAt.In(candihelper.AsiaJakartaLocalTime)
+	job.RunAt = job.RunAt.In(candihelper.AsiaJakartaLocalTime)
+	job.NextRetryAt = job.NextRetryAt.In(candihelper.AsiaJakartaLocalTime)
+	// histories may shared with stored job (file storage), format in new slice
+	job.Histories = append([]JobHistory(nil), job.Histories...)
+	for i := range job.Histories {
+		history := &job.Histories[i]
+		if history.TraceID != "" && defaultOption.JaegerTracingDashboard != "" &&
+			!strings.HasPrefix(history.TraceID, defaultOption.JaegerTracingDashboard) {
+			history.TraceID = fmt.Sprintf("%s/trace/%s", defaultOption.JaegerTracingDashboard, history.TraceID)
+		}
+		history.StartAt = history.StartAt.In(candihelper.AsiaJakartaLocalTime)
+		history.EndAt = history.EndAt.In(candihelper.AsiaJakartaLocalTime)
+	}
+}
+
+func validateJob(taskName string, maxRetry int, opt addJobOption) (workerIndex int, err error) {
+	task, ok := registeredTask[taskName]
+	if !ok {
+		var tasks []string
+		for taskName := range registeredTask {
+			tasks = append(tasks, taskName)
+		}
+		return 0, fmt.Errorf("task '%s' unregistered, task must one of [%s]", taskName, strings.Join(tasks, ", "))
+	}
+	if maxRetry <= 0 {
+		return 0, errors.New("Max retry must greater than 0")
+	}
+	if opt.priority < MinPriority || opt.priority > MaxPriority {
+		return 0, fmt.Errorf("Priority must between %d and %d", MinPriority, MaxPriority)
+	}
+	return task.workerIndex, nil
+}
+
+// validateJobArgs validate job arguments using args validator of registered task handler
+func validateJobArgs(taskName string, args []byte) error {
+	validate := registeredTask[taskName].handler.ArgsValidator
+	if validate == nil {
+		return nil
+	}
+	if err := validate(args); err != nil {
+		return fmt.Errorf("invalid arguments for task '%s': %v", taskName, err)
 	}
 	return nil
 }
 
+func createJob(taskName string, maxRetry int, args []byte, opt addJobOption) *Job {
+	var newJob Job
+	newJob.ID = uuid.New().String()
+	newJob.TaskName = taskName
+	newJob.Arguments = string(args)
+	newJob.MaxRetry = maxRetry
+	newJob.Interval = defaultInterval
+	newJob.Status = string(statusQueueing)
+	newJob.Priority = opt.priority
+	newJob.UniqueKey = opt.uniqueKey
+	newJob.WorkflowID, newJob.ParentID, newJob.NextSteps = opt.workflowID, opt.parentID, opt.nextSteps
+	newJob.BatchID, newJob.RecurringJobID = opt.batchID, opt.recurringJobID
+	if opt.timeout > 0 {
+		newJob.Timeout = opt.timeout.String()
+	}
+	newJob.CreatedAt = time.Now()
+
+	if opt.runAt.After(newJob.CreatedAt) {
+		newJob.RunAt = opt.runAt
+		newJob.Status = string(statusScheduled)
+	}
+	return &newJob
+}
+
+func registerNextJob(taskName string, workerIndex int) {
+	nextJobID := queue.NextJob(taskName)
+	if nextJobID != "" {
+		if nextJob, err := persistent.FindJobByID(context.Background(), nextJobID); err == nil {
+			registerJobToWorker(nextJob, workerIndex)
+		}
+	}
+}
+
+// registerJobToWorker activate worker of task after job interval, invalid or non positive interval is replaced with default interval
 func registerJobToWorker(job *Job, workerIndex int) {
-	interval, _ := time.ParseDuration(job.Interval)
+	interval, err := time.ParseDuration(job.Interval)
+	if err != nil || interval <= 0 {
+		interval, _ = time.ParseDuration(defaultInterval)
+	}
+	activateWorker(workerIndex, interval)
+}
+
+// activateWorker dispatch jobs of task in given worker index after interval
+func activateWorker(workerIndex int, interval time.Duration) {
+	workerMutex.Lock()
+	defer workerMutex.Unlock()
+
 	taskIndex := workerIndexTask[workerIndex]
 	if taskIndex.activeInterval == nil {
 		taskIndex.activeInterval = time.NewTicker(interval)
@@ -132,3 +370,13 @@ func registerJobToWorker(job *Job, workerIndex int) {
 	}
 	workers[workerIndex].Chan = reflect.ValueOf(taskIndex.activeInterval.C)
 }
+
+// deactivateWorker stop worker of task in given worker index until activated again
+func deactivateWorker(workerIndex int) {
+	workerMutex.Lock()
+	defer workerMutex.Unlock()
+
+	if taskIndex := workerIndexTask[workerIndex]; taskIndex.activeInterval != nil {
+		taskIndex.activeInterval.Stop()
+	}
+}
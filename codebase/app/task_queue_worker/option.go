@@ -6,14 +6,37 @@ import (
 
 type (
 	option struct {
-		JaegerTracingDashboard   string
-		MaxClientSubscriber      int
-		AutoRemoveClientInterval time.Duration
-		DashboardBanner          string
+		JaegerTracingDashboard    string
+		MaxClientSubscriber       int
+		AutoRemoveClientInterval  time.Duration
+		DashboardBanner           string
+		CheckScheduledJobInterval time.Duration
+		JobLeaseDuration          time.Duration
+		RetentionPolicies         []RetentionPolicy
+		RetentionSweepInterval    time.Duration
+		JobArchiver               JobArchiver
+		DashboardAuth             *DashboardAuth
+		RateLimiter               RateLimiter
 	}
 
 	// OptionFunc type
 	OptionFunc func(*option)
+
+	addJobOption struct {
+		runAt        time.Time
+		priority     int
+		uniqueKey    string
+		uniqueWindow time.Duration
+		timeout      time.Duration
+
+		workflowID, parentID string
+		nextSteps            []WorkflowStep
+		batchID              string
+		recurringJobID       string
+	}
+
+	// AddJobOptionFunc type
+	AddJobOptionFunc func(*addJobOption)
 )
 
 // SetJaegerTracingDashboard option func
@@ -43,3 +66,101 @@ func SetDashboardBanner(banner string) OptionFunc {
 		o.DashboardBanner = banner
 	}
 }
+
+// SetCheckScheduledJobInterval option func, interval for checking due scheduled jobs
+func SetCheckScheduledJobInterval(d time.Duration) OptionFunc {
+	return func(o *option) {
+		o.CheckScheduledJobInterval = d
+	}
+}
+
+// minJobLeaseDuration minimum job lease duration, lease is renewed every third of lease duration
+const minJobLeaseDuration = time.Second
+
+// SetJobLeaseDuration option func, running job lease is renewed periodically by worker instance,
+// job with expired lease (example: worker instance has died) will be claimed by another worker instance.
+// Lease duration must be at least 1 second (default 1 minute)
+func SetJobLeaseDuration(d time.Duration) OptionFunc {
+	return func(o *option) {
+		o.JobLeaseDuration = d
+	}
+}
+
+// SetRetentionPolicies option func, old finished jobs will be deleted periodically by background sweeper
+// based on given policies (example: delete SUCCESS jobs after 7 days and FAILURE jobs after 30 days)
+func SetRetentionPolicies(policies ...RetentionPolicy) OptionFunc {
+	return func(o *option) {
+		o.RetentionPolicies = append(o.RetentionPolicies, policies...)
+	}
+}
+
+// SetRetentionSweepInterval option func, interval for running retention sweeper
+func SetRetentionSweepInterval(d time.Duration) OptionFunc {
+	return func(o *option) {
+		o.RetentionSweepInterval = d
+	}
+}
+
+// SetJobArchiver option func, old jobs will be exported to given archiver before deleted by retention sweeper
+func SetJobArchiver(archiver JobArchiver) OptionFunc {
+	return func(o *option) {
+		o.JobArchiver = archiver
+	}
+}
+
+// SetDashboardAuth option func, protect dashboard GraphQL API using service middleware (basic or bearer auth with ACL),
+// panic if middleware is nil so dashboard is never served unprotected
+func SetDashboardAuth(auth DashboardAuth) OptionFunc {
+	if err := auth.Validate(); err != nil {
+		panic("Task Queue Worker: " + err.Error())
+	}
+	return func(o *option) {
+		o.DashboardAuth = &auth
+	}
+}
+
+// SetRateLimiter option func, storage of task rate limit bucket (default is in memory for each worker instance)
+func SetRateLimiter(limiter RateLimiter) OptionFunc {
+	return func(o *option) {
+		o.RateLimiter = limiter
+	}
+}
+
+// AddJobOptionRunAt add job option func, job will be executed at given time
+func AddJobOptionRunAt(t time.Time) AddJobOptionFunc {
+	return func(o *addJobOption) {
+		o.runAt = t
+	}
+}
+
+// AddJobOptionDelay add job option func, job will be executed after given delay
+func AddJobOptionDelay(d time.Duration) AddJobOptionFunc {
+	return func(o *addJobOption) {
+		o.runAt = time.Now().Add(d)
+	}
+}
+
+// AddJobOptionPriority add job option func, job with higher priority will be executed first
+// (must between MinPriority and MaxPriority)
+func AddJobOptionPriority(priority int) AddJobOptionFunc {
+	return func(o *addJobOption) {
+		o.priority = priority
+	}
+}
+
+// AddJobOptionUniqueKey add job option func, if job with same task name and unique key has been added
+// within given window (zero window means no time limit), existing job ID is returned instead of creating new job
+func AddJobOptionUniqueKey(uniqueKey string, window time.Duration) AddJobOptionFunc {
+	return func(o *addJobOption) {
+		o.uniqueKey = uniqueKey
+		o.uniqueWindow = window
+	}
+}
+
+// AddJobOptionTimeout add job option func, job execution context will be canceled after given timeout
+// (override timeout from task handler option)
+func AddJobOptionTimeout(timeout time.Duration) AddJobOptionFunc {
+	return func(o *addJobOption) {
+		o.timeout = timeout
+	}
+}
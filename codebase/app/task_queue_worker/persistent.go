@@ -1,6 +1,9 @@
 package taskqueueworker
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Persistent abstraction
 type Persistent interface {
@@ -9,6 +12,38 @@ type Persistent interface {
 	CountAllJob(ctx context.Context, filter Filter) int
 	AggregateAllTaskJob(ctx context.Context, filter Filter) (result []TaskResolver)
 	SaveJob(ctx context.Context, job *Job)
+	// SaveJobs save multiple jobs in one round trip
+	SaveJobs(ctx context.Context, jobs []*Job)
+	// SaveUniqueJob atomically save new job only if there is no other job with same task name and unique key
+	// created after given time (no time limit if zero), otherwise return existing job and new job is not saved
+	SaveUniqueJob(ctx context.Context, job *Job, createdAfter time.Time) (existingJob *Job, err error)
 	UpdateAllStatus(ctx context.Context, taskName string, currentStatus []JobStatusEnum, updatedStatus JobStatusEnum)
+	// CleanJob delete all finished jobs of given task, unique key used by deleted job also deleted
 	CleanJob(ctx context.Context, taskName string)
+	// DeleteJobs delete jobs with given ids, unique key used by deleted job also deleted
+	DeleteJobs(ctx context.Context, ids []string) error
+
+	// ClaimJob atomically mark queueing job (or retrying job with expired lease) as retrying
+	// and owned by given worker instance until lease time
+	ClaimJob(ctx context.Context, jobID, workerID string, leaseUntil time.Time) (job *Job, err error)
+	// RenewJobLease extend lease of retrying job owned by given worker instance
+	RenewJobLease(ctx context.Context, jobID, workerID string, leaseUntil time.Time) error
+	// UpdateJobProgress update progress of retrying job owned by given worker instance
+	UpdateJobProgress(ctx context.Context, jobID, workerID string, progress int, message string)
+
+	FindBatchByID(ctx context.Context, id string) (batch *Batch, err error)
+	SaveBatch(ctx context.Context, batch *Batch)
+
+	// FindAllRecurringJob find all recurring jobs, filtered by task name if not empty
+	FindAllRecurringJob(ctx context.Context, taskName string) (recurringJobs []RecurringJob)
+	FindRecurringJobByID(ctx context.Context, id string) (recurringJob *RecurringJob, err error)
+	SaveRecurringJob(ctx context.Context, recurringJob *RecurringJob)
+	// UpdateRecurringJobNextRun atomically update next run, last run, and last job ID of recurring job only if
+	// next run still equal with given previous next run (compare and set), return false if not updated
+	UpdateRecurringJobNextRun(ctx context.Context, recurringJob *RecurringJob, prevNextRunAt time.Time) (updated bool, err error)
+	DeleteRecurringJob(ctx context.Context, id string)
+
+	// FindAllPausedTask find name of all paused tasks
+	FindAllPausedTask(ctx context.Context) (taskNames []string)
+	SetTaskPaused(ctx context.Context, taskName string, isPaused bool)
 }
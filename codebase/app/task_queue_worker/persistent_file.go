@@ -0,0 +1,624 @@
+package taskqueueworker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/logger"
+	"github.com/google/uuid"
+)
+
+const (
+	fileLogSave      = "save"
+	fileLogDelete    = "delete"
+	fileLogSaveBatch = "save_batch"
+	fileLogSaveRecur = "save_recurring_job"
+	fileLogDelRecur  = "delete_recurring_job"
+	fileLogPause     = "pause_task"
+	fileLogResume    = "resume_task"
+
+	// fileCompactThreshold minimum total log records before log file compacted
+	fileCompactThreshold = 1000
+)
+
+// FileStorage abstraction for embedded storage, can be used as queue storage and persistent
+type FileStorage interface {
+	QueueStorage
+	Persistent
+}
+
+type (
+	fileStorage struct {
+		QueueStorage
+
+		mu         sync.RWMutex
+		filePath   string
+		file       *os.File
+		jobs       map[string]*Job
+		batches    map[string]*Batch
+		recurJobs  map[string]*RecurringJob
+		paused     map[string]bool
+		totalLines int
+	}
+
+	fileLogRecord struct {
+		Op    string `json:"op"`
+		ID    string `json:"id,omitempty"`
+		Job   *Job   `json:"job,omitempty"`
+		Batch *Batch `json:"batch,omitempty"`
+
+		RecurringJob *RecurringJob `json:"recurring_job,omitempty"`
+	}
+)
+
+// NewFileStorage create embedded single node storage, all jobs is stored in append only log file in given path
+// (synced to disk on each write). Queue itself is not persisted, it is only stored in memory and rebuilt from saved
+// queueing & retrying jobs when worker started, so queue order before restart is not kept.
+// File storage cannot be shared by multiple worker instances
+func NewFileStorage(filePath string) FileStorage {
+	s := &fileStorage{
+		QueueStorage: NewInMemQueue(),
+		filePath:     filePath,
+		jobs:         make(map[string]*Job),
+		batches:      make(map[string]*Batch),
+		recurJobs:    make(map[string]*RecurringJob),
+		paused:       make(map[string]bool),
+	}
+
+	if err := s.load(); err != nil {
+		panic(fmt.Errorf("task queue worker: failed when load file storage %s: %v", filePath, err))
+	}
+	if err := s.compact(); err != nil {
+		panic(fmt.Errorf("task queue worker: failed when compact file storage %s: %v", filePath, err))
+	}
+	return s
+}
+
+func (s *fileStorage) FindAllJob(ctx context.Context, filter Filter) (jobs []Job) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, job := range s.jobs {
+		if s.matchFilter(job, filter) {
+			jobs = append(jobs, *job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+	})
+
+	if !filter.ShowAll {
+		offset := (filter.Page - 1) * filter.Limit
+		if offset < 0 || offset >= len(jobs) {
+			return nil
+		}
+		if end := offset + filter.Limit; end < len(jobs) {
+			jobs = jobs[offset:end]
+		} else {
+			jobs = jobs[offset:]
+		}
+	}
+	return
+}
+
+func (s *fileStorage) FindJobByID(ctx context.Context, id string) (*Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, errors.New("job not found")
+	}
+	res := *job
+	return &res, nil
+}
+
+func (s *fileStorage) CountAllJob(ctx context.Context, filter Filter) (count int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, job := range s.jobs {
+		if s.matchFilter(job, filter) {
+			count++
+		}
+	}
+	return
+}
+
+func (s *fileStorage) AggregateAllTaskJob(ctx context.Context, filter Filter) (result []TaskResolver) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result = make([]TaskResolver, len(filter.TaskNameList))
+	mapper := make(map[string]int, len(filter.TaskNameList))
+	for i, task := range filter.TaskNameList {
+		result[i].Name = task
+		mapper[task] = i
+	}
+
+	for _, job := range s.jobs {
+		idx, ok := mapper[job.TaskName]
+		if !ok || !s.matchFilter(job, filter) {
+			continue
+		}
+
+		res := &result[idx]
+		switch JobStatusEnum(job.Status) {
+		case statusSuccess:
+			res.Detail.Success++
+		case statusQueueing:
+			res.Detail.Queueing++
+		case statusRetrying:
+			res.Detail.Retrying++
+		case statusFailure:
+			res.Detail.Failure++
+		case statusStopped:
+			res.Detail.Stopped++
+		case statusScheduled:
+			res.Detail.Scheduled++
+		default:
+			continue
+		}
+		res.TotalJobs++
+	}
+
+	return
+}
+
+func (s *fileStorage) SaveJob(ctx context.Context, job *Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if job.ID == "" {
+		job.ID = uuid.New().String()
+	}
+	saved := *job
+	s.jobs[job.ID] = &saved
+	s.appendLog(fileLogRecord{Op: fileLogSave, Job: &saved})
+}
+
+func (s *fileStorage) SaveJobs(ctx context.Context, jobs []*Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records := make([]fileLogRecord, 0, len(jobs))
+	for _, job := range jobs {
+		if job.ID == "" {
+			job.ID = uuid.New().String()
+		}
+		saved := *job
+		s.jobs[job.ID] = &saved
+		records = append(records, fileLogRecord{Op: fileLogSave, Job: &saved})
+	}
+	s.appendLog(records...)
+}
+
+func (s *fileStorage) SaveUniqueJob(ctx context.Context, job *Job, createdAfter time.Time) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var existing *Job
+	for _, j := range s.jobs {
+		if j.TaskName != job.TaskName || j.UniqueKey != job.UniqueKey || j.CreatedAt.Before(createdAfter) {
+			continue
+		}
+		if existing == nil || j.CreatedAt.After(existing.CreatedAt) {
+			existing = j
+		}
+	}
+	if existing != nil {
+		res := *existing
+		return &res, nil
+	}
+
+	saved := *job
+	s.jobs[job.ID] = &saved
+	s.appendLog(fileLogRecord{Op: fileLogSave, Job: &saved})
+	return nil, nil
+}
+
+func (s *fileStorage) UpdateAllStatus(ctx context.Context, taskName string, currentStatus []JobStatusEnum, updatedStatus JobStatusEnum) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var records []fileLogRecord
+	for _, job := range s.jobs {
+		if taskName != "" && job.TaskName != taskName {
+			continue
+		}
+		for _, status := range currentStatus {
+			if job.Status == string(status) {
+				job.Status = string(updatedStatus)
+				job.Retries = 0
+				records = append(records, fileLogRecord{Op: fileLogSave, Job: job})
+				break
+			}
+		}
+	}
+	s.appendLog(records...)
+}
+
+func (s *fileStorage) CleanJob(ctx context.Context, taskName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var records []fileLogRecord
+	for id, job := range s.jobs {
+		if job.TaskName != taskName || job.Status == string(statusRetrying) ||
+			job.Status == string(statusQueueing) || job.Status == string(statusScheduled) {
+			continue
+		}
+		delete(s.jobs, id)
+		records = append(records, fileLogRecord{Op: fileLogDelete, ID: id})
+	}
+	s.appendLog(records...)
+}
+
+func (s *fileStorage) DeleteJobs(ctx context.Context, ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var records []fileLogRecord
+	for _, id := range ids {
+		if _, ok := s.jobs[id]; !ok {
+			continue
+		}
+		delete(s.jobs, id)
+		records = append(records, fileLogRecord{Op: fileLogDelete, ID: id})
+	}
+	s.appendLog(records...)
+	return nil
+}
+
+func (s *fileStorage) ClaimJob(ctx context.Context, jobID, workerID string, leaseUntil time.Time) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[jobID]
+	if !ok {
+		return nil, errJobNotClaimed
+	}
+	isExpiredLease := job.Status == string(statusRetrying) && job.LeaseUntil.Before(time.Now())
+	if job.Status != string(statusQueueing) && !isExpiredLease {
+		return nil, errJobNotClaimed
+	}
+
+	job.Status = string(statusRetrying)
+	job.WorkerID = workerID
+	job.LeaseUntil = leaseUntil
+	s.appendLog(fileLogRecord{Op: fileLogSave, Job: job})
+	res := *job
+	return &res, nil
+}
+
+func (s *fileStorage) RenewJobLease(ctx context.Context, jobID, workerID string, leaseUntil time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[jobID]
+	if !ok || job.WorkerID != workerID || job.Status != string(statusRetrying) {
+		return errJobLeaseLost
+	}
+	job.LeaseUntil = leaseUntil
+	s.appendLog(fileLogRecord{Op: fileLogSave, Job: job})
+	return nil
+}
+
+func (s *fileStorage) UpdateJobProgress(ctx context.Context, jobID, workerID string, progress int, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[jobID]
+	if !ok || job.WorkerID != workerID || job.Status != string(statusRetrying) {
+		return
+	}
+	job.Progress, job.ProgressMessage = progress, message
+	s.appendLog(fileLogRecord{Op: fileLogSave, Job: job})
+}
+
+func (s *fileStorage) FindBatchByID(ctx context.Context, id string) (*Batch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	batch, ok := s.batches[id]
+	if !ok {
+		return nil, errors.New("batch not found")
+	}
+	res := *batch
+	return &res, nil
+}
+
+func (s *fileStorage) SaveBatch(ctx context.Context, batch *Batch) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := *batch
+	s.batches[batch.ID] = &saved
+	s.appendLog(fileLogRecord{Op: fileLogSaveBatch, Batch: &saved})
+}
+
+func (s *fileStorage) FindAllRecurringJob(ctx context.Context, taskName string) (recurringJobs []RecurringJob) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, recurringJob := range s.recurJobs {
+		if taskName == "" || recurringJob.TaskName == taskName {
+			recurringJobs = append(recurringJobs, *recurringJob)
+		}
+	}
+	sort.Slice(recurringJobs, func(i, j int) bool {
+		return recurringJobs[i].CreatedAt.Before(recurringJobs[j].CreatedAt)
+	})
+	return
+}
+
+func (s *fileStorage) FindRecurringJobByID(ctx context.Context, id string) (*RecurringJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	recurringJob, ok := s.recurJobs[id]
+	if !ok {
+		return nil, errors.New("recurring job not found")
+	}
+	res := *recurringJob
+	return &res, nil
+}
+
+func (s *fileStorage) SaveRecurringJob(ctx context.Context, recurringJob *RecurringJob) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := *recurringJob
+	s.recurJobs[recurringJob.ID] = &saved
+	s.appendLog(fileLogRecord{Op: fileLogSaveRecur, RecurringJob: &saved})
+}
+
+func (s *fileStorage) UpdateRecurringJobNextRun(ctx context.Context, recurringJob *RecurringJob, prevNextRunAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.recurJobs[recurringJob.ID]
+	if !ok || !current.NextRunAt.Equal(prevNextRunAt) {
+		return false, nil
+	}
+	saved := *current
+	saved.NextRunAt, saved.LastRunAt, saved.LastJobID = recurringJob.NextRunAt, recurringJob.LastRunAt, recurringJob.LastJobID
+	s.recurJobs[recurringJob.ID] = &saved
+	s.appendLog(fileLogRecord{Op: fileLogSaveRecur, RecurringJob: &saved})
+	return true, nil
+}
+
+func (s *fileStorage) DeleteRecurringJob(ctx context.Context, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.recurJobs, id)
+	s.appendLog(fileLogRecord{Op: fileLogDelRecur, ID: id})
+}
+
+func (s *fileStorage) FindAllPausedTask(ctx context.Context) (taskNames []string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for taskName := range s.paused {
+		taskNames = append(taskNames, taskName)
+	}
+	sort.Strings(taskNames)
+	return
+}
+
+func (s *fileStorage) SetTaskPaused(ctx context.Context, taskName string, isPaused bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.paused[taskName] == isPaused {
+		return
+	}
+	if isPaused {
+		s.paused[taskName] = true
+		s.appendLog(fileLogRecord{Op: fileLogPause, ID: taskName})
+	} else {
+		delete(s.paused, taskName)
+		s.appendLog(fileLogRecord{Op: fileLogResume, ID: taskName})
+	}
+}
+
+func (s *fileStorage) matchFilter(job *Job, f Filter) bool {
+	if f.TaskName != "" {
+		if job.TaskName != f.TaskName {
+			return false
+		}
+	} else if len(f.TaskNameList) > 0 && !s.contains(f.TaskNameList, job.TaskName) {
+		return false
+	}
+
+	if f.BatchID != "" && job.BatchID != f.BatchID {
+		return false
+	}
+	if f.Search != nil && *f.Search != "" &&
+		!strings.Contains(strings.ToLower(job.Arguments), strings.ToLower(*f.Search)) {
+		return false
+	}
+	if len(f.Status) > 0 && !s.contains(f.Status, job.Status) {
+		return false
+	}
+	if f.CreatedAfter != nil && job.CreatedAt.Before(*f.CreatedAfter) {
+		return false
+	}
+	if f.CreatedBefore != nil && !job.CreatedAt.Before(*f.CreatedBefore) {
+		return false
+	}
+	if f.FinishedBefore != nil && (!job.FinishedAt.Before(*f.FinishedBefore) || !job.CreatedAt.Before(*f.FinishedBefore)) {
+		return false
+	}
+	if f.RunAtBefore != nil && job.RunAt.After(*f.RunAtBefore) {
+		return false
+	}
+	if f.LeaseExpiredBefore != nil && !job.LeaseUntil.Before(*f.LeaseExpiredBefore) {
+		return false
+	}
+	return true
+}
+
+func (s *fileStorage) contains(list []string, str string) bool {
+	for _, l := range list {
+		if l == str {
+			return true
+		}
+	}
+	return false
+}
+
+// load replay all log records from storage file
+func (s *fileStorage) load() error {
+	f, err := os.Open(s.filePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			var record fileLogRecord
+			// skip incomplete record, possible when process killed while writing log
+			if errUnmarshal := json.Unmarshal(line, &record); errUnmarshal == nil {
+				switch record.Op {
+				case fileLogSave:
+					if record.Job != nil {
+						s.jobs[record.Job.ID] = record.Job
+					}
+				case fileLogDelete:
+					delete(s.jobs, record.ID)
+				case fileLogSaveBatch:
+					if record.Batch != nil {
+						s.batches[record.Batch.ID] = record.Batch
+					}
+				case fileLogSaveRecur:
+					if record.RecurringJob != nil {
+						s.recurJobs[record.RecurringJob.ID] = record.RecurringJob
+					}
+				case fileLogDelRecur:
+					delete(s.recurJobs, record.ID)
+				case fileLogPause:
+					s.paused[record.ID] = true
+				case fileLogResume:
+					delete(s.paused, record.ID)
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// compact rewrite storage file with only latest state of all jobs
+func (s *fileStorage) compact() error {
+	tmpPath := s.filePath + ".tmp"
+	tmpFile, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(tmpFile)
+	encoder := json.NewEncoder(writer)
+	for _, job := range s.jobs {
+		if err := encoder.Encode(fileLogRecord{Op: fileLogSave, Job: job}); err != nil {
+			tmpFile.Close()
+			return err
+		}
+	}
+	for _, batch := range s.batches {
+		if err := encoder.Encode(fileLogRecord{Op: fileLogSaveBatch, Batch: batch}); err != nil {
+			tmpFile.Close()
+			return err
+		}
+	}
+	for _, recurringJob := range s.recurJobs {
+		if err := encoder.Encode(fileLogRecord{Op: fileLogSaveRecur, RecurringJob: recurringJob}); err != nil {
+			tmpFile.Close()
+			return err
+		}
+	}
+	for taskName := range s.paused {
+		if err := encoder.Encode(fileLogRecord{Op: fileLogPause, ID: taskName}); err != nil {
+			tmpFile.Close()
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		return err
+	}
+
+	// current log file is kept open until compacted file replace it, so log still can be appended when rename failed
+	if err := os.Rename(tmpPath, s.filePath); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if s.file != nil {
+		s.file.Close()
+	}
+	s.file = tmpFile
+	s.totalLines = s.totalRecords()
+	return nil
+}
+
+// appendLog write records to end of log file, file is synced once for all given records
+func (s *fileStorage) appendLog(records ...fileLogRecord) {
+	if len(records) == 0 {
+		return
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			logger.LogE(err.Error())
+			return
+		}
+	}
+	if _, err := s.file.Write(buf.Bytes()); err != nil {
+		logger.LogE(err.Error())
+		return
+	}
+	if err := s.file.Sync(); err != nil {
+		logger.LogE(err.Error())
+		return
+	}
+
+	s.totalLines += len(records)
+	if s.totalLines > fileCompactThreshold && s.totalLines > 2*s.totalRecords() {
+		if err := s.compact(); err != nil {
+			logger.LogE(err.Error())
+		}
+	}
+}
+
+// totalRecords total latest state of all data
+func (s *fileStorage) totalRecords() int {
+	return len(s.jobs) + len(s.batches) + len(s.recurJobs) + len(s.paused)
+}
@@ -0,0 +1,87 @@
+package taskqueueworker
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileStorage(t *testing.T) {
+	ctx := context.Background()
+	filePath := filepath.Join(t.TempDir(), "task_queue_worker.db")
+
+	storage := NewFileStorage(filePath)
+	storage.SaveJob(ctx, &Job{ID: "1", TaskName: "task-one", Status: string(statusQueueing), CreatedAt: time.Now()})
+	storage.SaveJob(ctx, &Job{ID: "2", TaskName: "task-one", Status: string(statusSuccess), CreatedAt: time.Now()})
+	storage.SaveJob(ctx, &Job{ID: "3", TaskName: "task-two", Status: string(statusFailure), CreatedAt: time.Now(), UniqueKey: "key",
+		Histories: []JobHistory{{Retries: 1, Status: string(statusFailure), Error: "error"}}})
+	storage.UpdateAllStatus(ctx, "task-two", []JobStatusEnum{statusFailure}, statusQueueing)
+	storage.CleanJob(ctx, "task-one")
+
+	// reopen storage, all jobs must be loaded from file
+	storage = NewFileStorage(filePath)
+	assert.Equal(t, 2, storage.CountAllJob(ctx, Filter{}))
+	_, err := storage.FindJobByID(ctx, "2")
+	assert.Error(t, err)
+
+	job, err := storage.FindJobByID(ctx, "3")
+	assert.NoError(t, err)
+	assert.Equal(t, string(statusQueueing), job.Status)
+	assert.Len(t, job.Histories, 1)
+
+	result := storage.AggregateAllTaskJob(ctx, Filter{TaskNameList: []string{"task-one", "task-two"}})
+	assert.Equal(t, 1, result[0].Detail.Queueing)
+	assert.Equal(t, 1, result[1].Detail.Queueing)
+
+	existingJob, err := storage.SaveUniqueJob(ctx, &Job{ID: "4", TaskName: "task-two", UniqueKey: "key", CreatedAt: time.Now()},
+		time.Now().Add(-time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, "3", existingJob.ID)
+	existingJob, err = storage.SaveUniqueJob(ctx, &Job{ID: "5", TaskName: "task-two", UniqueKey: "key", CreatedAt: time.Now()},
+		time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Nil(t, existingJob)
+	_, err = storage.FindJobByID(ctx, "5")
+	assert.NoError(t, err)
+
+	jobs := storage.FindAllJob(ctx, Filter{Page: 1, Limit: 1, Status: []string{string(statusQueueing)}})
+	assert.Len(t, jobs, 1)
+}
+
+func TestFileStorageCompactFailed(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "task_queue_worker.db")
+	storage := NewFileStorage(filePath).(*fileStorage)
+	storage.SaveJob(ctx, &Job{ID: "1", TaskName: "task-one", Status: string(statusQueueing)})
+
+	// compacted file cannot replace directory, current log file still used
+	storage.filePath = dir
+	assert.Error(t, storage.compact())
+	storage.SaveJob(ctx, &Job{ID: "2", TaskName: "task-one", Status: string(statusQueueing)})
+
+	storage = NewFileStorage(filePath).(*fileStorage)
+	assert.Equal(t, 2, storage.CountAllJob(ctx, Filter{}))
+
+	// log appended to compacted file
+	assert.NoError(t, storage.compact())
+	storage.SaveJob(ctx, &Job{ID: "3", TaskName: "task-one", Status: string(statusQueueing)})
+	assert.Equal(t, 3, NewFileStorage(filePath).CountAllJob(ctx, Filter{}))
+}
+
+func TestFileStorageSaveJobs(t *testing.T) {
+	ctx := context.Background()
+	filePath := filepath.Join(t.TempDir(), "task_queue_worker.db")
+	storage := NewFileStorage(filePath).(*fileStorage)
+
+	// all records written at once, each record still in one line
+	storage.SaveJobs(ctx, []*Job{{ID: "1", TaskName: "task-one"}, {ID: "2", TaskName: "task-one"}, {TaskName: "task-one"}})
+	assert.NoError(t, storage.DeleteJobs(ctx, []string{"1", "2", "unknown"}))
+	storage.SaveJobs(ctx, nil)
+	assert.Equal(t, 5, storage.totalLines)
+
+	assert.Equal(t, 1, NewFileStorage(filePath).CountAllJob(ctx, Filter{}))
+}
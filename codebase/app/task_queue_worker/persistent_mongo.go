@@ -2,7 +2,6 @@ package taskqueueworker
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	"github.com/golangid/candi/candihelper"
@@ -14,7 +13,13 @@ import (
 )
 
 const (
-	mongoColl = "task_queue_worker_jobs"
+	mongoColl      = "task_queue_worker_jobs"
+	mongoBatchColl = "task_queue_worker_batches"
+	mongoRecurColl = "task_queue_worker_recurring_jobs"
+	mongoTaskColl  = "task_queue_worker_tasks"
+
+	// mongoUniqueKeyColl unique key of job with unique key option, guarded by unique index
+	mongoUniqueKeyColl = "task_queue_worker_job_unique_keys"
 )
 
 type mongoPersistent struct {
@@ -57,12 +62,37 @@ func NewMongoPersistent(db *mongo.Database) Persistent {
 				{Key: "status", Value: 1},
 			},
 		},
+		{
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "run_at", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "lease_until", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "batch_id", Value: 1},
+				{Key: "status", Value: 1},
+			},
+		},
 	}
 
 	indexView := db.Collection(mongoColl).Indexes()
 	for _, idx := range indexes {
 		indexView.CreateOne(context.Background(), idx)
 	}
+	db.Collection(mongoUniqueKeyColl).Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "task_name", Value: 1},
+			{Key: "unique_key", Value: 1},
+		},
+		Options: uniqueOpts,
+	})
 	return &mongoPersistent{db}
 }
 
@@ -84,17 +114,6 @@ func (s *mongoPersistent) FindAllJob(ctx context.Context, filter Filter) (jobs [
 	for cur.Next(ctx) {
 		var job Job
 		cur.Decode(&job)
-		if job.Status == string(statusSuccess) {
-			job.Error = ""
-		}
-		if delay, err := time.ParseDuration(job.Interval); err == nil && job.Status == string(statusQueueing) {
-			job.NextRetryAt = time.Now().Add(delay).In(candihelper.AsiaJakartaLocalTime).Format(time.RFC3339)
-		}
-		if job.TraceID != "" && defaultOption.JaegerTracingDashboard != "" {
-			job.TraceID = fmt.Sprintf("%s/trace/%s", defaultOption.JaegerTracingDashboard, job.TraceID)
-		}
-		job.CreatedAt = job.CreatedAt.In(candihelper.AsiaJakartaLocalTime)
-		job.FinishedAt = job.FinishedAt.In(candihelper.AsiaJakartaLocalTime)
 		jobs = append(jobs, job)
 	}
 
@@ -120,6 +139,7 @@ func (s *mongoPersistent) AggregateAllTaskJob(ctx context.Context, filter Filter
 				"retrying":  bson.M{"$cond": bson.M{"if": bson.M{"$eq": []interface{}{"$status", statusRetrying}}, "then": 1, "else": 0}},
 				"failure":   bson.M{"$cond": bson.M{"if": bson.M{"$eq": []interface{}{"$status", statusFailure}}, "then": 1, "else": 0}},
 				"stopped":   bson.M{"$cond": bson.M{"if": bson.M{"$eq": []interface{}{"$status", statusStopped}}, "then": 1, "else": 0}},
+				"scheduled": bson.M{"$cond": bson.M{"if": bson.M{"$eq": []interface{}{"$status", statusScheduled}}, "then": 1, "else": 0}},
 			},
 		},
 		{
@@ -140,6 +160,9 @@ func (s *mongoPersistent) AggregateAllTaskJob(ctx context.Context, filter Filter
 				"stopped": bson.M{
 					"$sum": "$stopped",
 				},
+				"scheduled": bson.M{
+					"$sum": "$scheduled",
+				},
 			},
 		},
 	}
@@ -162,25 +185,27 @@ func (s *mongoPersistent) AggregateAllTaskJob(ctx context.Context, filter Filter
 
 	for csr.Next(ctx) {
 		var obj struct {
-			TaskName string `bson:"_id"`
-			Success  int    `bson:"success"`
-			Queueing int    `bson:"queueing"`
-			Retrying int    `bson:"retrying"`
-			Failure  int    `bson:"failure"`
-			Stopped  int    `bson:"stopped"`
+			TaskName  string `bson:"_id"`
+			Success   int    `bson:"success"`
+			Queueing  int    `bson:"queueing"`
+			Retrying  int    `bson:"retrying"`
+			Failure   int    `bson:"failure"`
+			Stopped   int    `bson:"stopped"`
+			Scheduled int    `bson:"scheduled"`
 		}
 		csr.Decode(&obj)
 
 		if idx, ok := mapper[obj.TaskName]; ok {
 			res := TaskResolver{
 				Name:      obj.TaskName,
-				TotalJobs: obj.Success + obj.Queueing + obj.Retrying + obj.Failure + obj.Stopped,
+				TotalJobs: obj.Success + obj.Queueing + obj.Retrying + obj.Failure + obj.Stopped + obj.Scheduled,
 			}
 			res.Detail.Success = obj.Success
 			res.Detail.Queueing = obj.Queueing
 			res.Detail.Retrying = obj.Retrying
 			res.Detail.Failure = obj.Failure
 			res.Detail.Stopped = obj.Stopped
+			res.Detail.Scheduled = obj.Scheduled
 			result[idx] = res
 		}
 	}
@@ -212,6 +237,72 @@ func (s *mongoPersistent) SaveJob(ctx context.Context, job *Job) {
 	}
 }
 
+func (s *mongoPersistent) SaveJobs(ctx context.Context, jobs []*Job) {
+	if len(jobs) == 0 {
+		return
+	}
+
+	models := make([]mongo.WriteModel, len(jobs))
+	for i, job := range jobs {
+		if job.ID == "" {
+			job.ID = primitive.NewObjectID().Hex()
+		}
+		models[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": job.ID}).
+			SetUpdate(bson.M{"$set": job}).
+			SetUpsert(true)
+	}
+	if _, err := s.db.Collection(mongoColl).BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+		logger.LogE(err.Error())
+	}
+}
+
+func (s *mongoPersistent) SaveUniqueJob(ctx context.Context, job *Job, createdAfter time.Time) (*Job, error) {
+	uniqueKeyFilter := bson.M{"task_name": job.TaskName, "unique_key": job.UniqueKey}
+
+	// take over unique key used by job created before given time, return duplicate key error if unique key is still used
+	_, err := s.db.Collection(mongoUniqueKeyColl).UpdateOne(ctx,
+		bson.M{"task_name": job.TaskName, "unique_key": job.UniqueKey, "created_at": bson.M{"$lt": createdAfter}},
+		bson.M{"$set": bson.M{"job_id": job.ID, "created_at": job.CreatedAt}},
+		options.Update().SetUpsert(true),
+	)
+	if err == nil {
+		s.SaveJob(ctx, job)
+		return nil, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return nil, err
+	}
+
+	var uniqueKey struct {
+		JobID string `bson:"job_id"`
+	}
+	if err := s.db.Collection(mongoUniqueKeyColl).FindOne(ctx, uniqueKeyFilter).Decode(&uniqueKey); err != nil {
+		return nil, err
+	}
+	existingJob, err := s.FindJobByID(ctx, uniqueKey.JobID)
+	if err == nil {
+		return existingJob, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	// existing job has been deleted, take over unique key if not taken by another worker instance
+	uniqueKeyFilter["job_id"] = uniqueKey.JobID
+	res, err := s.db.Collection(mongoUniqueKeyColl).UpdateOne(ctx, uniqueKeyFilter,
+		bson.M{"$set": bson.M{"job_id": job.ID, "created_at": job.CreatedAt}},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if res.MatchedCount == 0 {
+		return nil, errUniqueKeyTaken
+	}
+	s.SaveJob(ctx, job)
+	return nil, nil
+}
+
 func (s *mongoPersistent) UpdateAllStatus(ctx context.Context, taskName string, currentStatus []JobStatusEnum, updatedStatus JobStatusEnum) {
 	filter := bson.M{}
 
@@ -242,10 +333,214 @@ func (s *mongoPersistent) CleanJob(ctx context.Context, taskName string) {
 	query := bson.M{
 		"$and": []bson.M{
 			{"task_name": taskName},
-			{"status": bson.M{"$nin": []JobStatusEnum{statusRetrying, statusQueueing}}},
+			{"status": bson.M{"$nin": []JobStatusEnum{statusRetrying, statusQueueing, statusScheduled}}},
+		},
+	}
+	// jobs deleted by id in chunks, so that unique keys of deleted jobs also deleted
+	findOptions := options.Find().SetProjection(bson.M{"_id": 1}).SetLimit(retentionSweepLimit)
+	for {
+		cur, err := s.db.Collection(mongoColl).Find(ctx, query, findOptions)
+		if err != nil {
+			logger.LogE(err.Error())
+			return
+		}
+		var ids []string
+		for cur.Next(ctx) {
+			var job Job
+			cur.Decode(&job)
+			ids = append(ids, job.ID)
+		}
+		cur.Close(ctx)
+
+		if len(ids) == 0 {
+			return
+		}
+		if err := s.DeleteJobs(ctx, ids); err != nil {
+			logger.LogE(err.Error())
+			return
+		}
+	}
+}
+
+func (s *mongoPersistent) DeleteJobs(ctx context.Context, ids []string) error {
+
+	// unique key still used by deleted job is deleted, unique key taken over by another job is kept
+	if _, err := s.db.Collection(mongoUniqueKeyColl).DeleteMany(ctx, bson.M{"job_id": bson.M{"$in": ids}}); err != nil {
+		return err
+	}
+	_, err := s.db.Collection(mongoColl).DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	return err
+}
+
+func (s *mongoPersistent) ClaimJob(ctx context.Context, jobID, workerID string, leaseUntil time.Time) (job *Job, err error) {
+
+	filter := bson.M{
+		"_id": jobID,
+		"$or": []bson.M{
+			{"status": statusQueueing},
+			{"status": statusRetrying, "lease_until": bson.M{"$not": bson.M{"$gte": time.Now()}}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{"status": statusRetrying, "worker_id": workerID, "lease_until": leaseUntil},
+	}
+
+	job = &Job{}
+	err = s.db.Collection(mongoColl).FindOneAndUpdate(ctx, filter, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(job)
+	if err == mongo.ErrNoDocuments {
+		err = errJobNotClaimed
+	}
+	return
+}
+
+func (s *mongoPersistent) RenewJobLease(ctx context.Context, jobID, workerID string, leaseUntil time.Time) error {
+
+	res, err := s.db.Collection(mongoColl).UpdateOne(ctx,
+		bson.M{"_id": jobID, "worker_id": workerID, "status": statusRetrying},
+		bson.M{"$set": bson.M{"lease_until": leaseUntil}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errJobLeaseLost
+	}
+	return nil
+}
+
+func (s *mongoPersistent) UpdateJobProgress(ctx context.Context, jobID, workerID string, progress int, message string) {
+
+	_, err := s.db.Collection(mongoColl).UpdateOne(ctx,
+		bson.M{"_id": jobID, "worker_id": workerID, "status": statusRetrying},
+		bson.M{"$set": bson.M{"progress": progress, "progress_message": message}},
+	)
+	if err != nil {
+		logger.LogE(err.Error())
+	}
+}
+
+func (s *mongoPersistent) FindBatchByID(ctx context.Context, id string) (batch *Batch, err error) {
+
+	batch = &Batch{}
+	err = s.db.Collection(mongoBatchColl).FindOne(ctx, bson.M{"_id": id}).Decode(batch)
+	return
+}
+
+func (s *mongoPersistent) SaveBatch(ctx context.Context, batch *Batch) {
+
+	_, err := s.db.Collection(mongoBatchColl).UpdateOne(ctx,
+		bson.M{
+			"_id": batch.ID,
+		},
+		bson.M{
+			"$set": batch,
+		}, options.Update().SetUpsert(true))
+	if err != nil {
+		logger.LogE(err.Error())
+	}
+}
+
+func (s *mongoPersistent) FindAllRecurringJob(ctx context.Context, taskName string) (recurringJobs []RecurringJob) {
+
+	filter := bson.M{}
+	if taskName != "" {
+		filter["task_name"] = taskName
+	}
+	cur, err := s.db.Collection(mongoRecurColl).Find(ctx, filter, &options.FindOptions{Sort: bson.M{"created_at": 1}})
+	if err != nil {
+		logger.LogE(err.Error())
+		return
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var recurringJob RecurringJob
+		cur.Decode(&recurringJob)
+		recurringJobs = append(recurringJobs, recurringJob)
+	}
+	return
+}
+
+func (s *mongoPersistent) FindRecurringJobByID(ctx context.Context, id string) (recurringJob *RecurringJob, err error) {
+
+	recurringJob = &RecurringJob{}
+	err = s.db.Collection(mongoRecurColl).FindOne(ctx, bson.M{"_id": id}).Decode(recurringJob)
+	return
+}
+
+func (s *mongoPersistent) SaveRecurringJob(ctx context.Context, recurringJob *RecurringJob) {
+
+	_, err := s.db.Collection(mongoRecurColl).UpdateOne(ctx,
+		bson.M{
+			"_id": recurringJob.ID,
+		},
+		bson.M{
+			"$set": recurringJob,
+		}, options.Update().SetUpsert(true))
+	if err != nil {
+		logger.LogE(err.Error())
+	}
+}
+
+func (s *mongoPersistent) UpdateRecurringJobNextRun(ctx context.Context, recurringJob *RecurringJob, prevNextRunAt time.Time) (bool, error) {
+
+	res, err := s.db.Collection(mongoRecurColl).UpdateOne(ctx,
+		bson.M{
+			"_id":         recurringJob.ID,
+			"next_run_at": prevNextRunAt,
+		},
+		bson.M{
+			"$set": bson.M{
+				"next_run_at": recurringJob.NextRunAt,
+				"last_run_at": recurringJob.LastRunAt,
+				"last_job_id": recurringJob.LastJobID,
+			},
+		})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+func (s *mongoPersistent) DeleteRecurringJob(ctx context.Context, id string) {
+
+	if _, err := s.db.Collection(mongoRecurColl).DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+		logger.LogE(err.Error())
+	}
+}
+
+func (s *mongoPersistent) FindAllPausedTask(ctx context.Context) (taskNames []string) {
+
+	cur, err := s.db.Collection(mongoTaskColl).Find(ctx, bson.M{"is_paused": true})
+	if err != nil {
+		logger.LogE(err.Error())
+		return
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var task struct {
+			Name string `bson:"_id"`
+		}
+		cur.Decode(&task)
+		taskNames = append(taskNames, task.Name)
+	}
+	return
+}
+
+func (s *mongoPersistent) SetTaskPaused(ctx context.Context, taskName string, isPaused bool) {
+
+	_, err := s.db.Collection(mongoTaskColl).UpdateOne(ctx,
+		bson.M{
+			"_id": taskName,
 		},
+		bson.M{
+			"$set": bson.M{"is_paused": isPaused, "updated_at": time.Now()},
+		}, options.Update().SetUpsert(true))
+	if err != nil {
+		logger.LogE(err.Error())
 	}
-	s.db.Collection(mongoColl).DeleteMany(ctx, query)
 }
 
 func (s *mongoPersistent) toBsonFilter(f Filter) bson.M {
@@ -263,6 +558,11 @@ func (s *mongoPersistent) toBsonFilter(f Filter) bson.M {
 		})
 	}
 
+	if f.BatchID != "" {
+		pipeQuery = append(pipeQuery, bson.M{
+			"batch_id": f.BatchID,
+		})
+	}
 	if f.Search != nil && *f.Search != "" {
 		pipeQuery = append(pipeQuery, bson.M{
 			"arguments": primitive.Regex{Pattern: *f.Search, Options: "i"},
@@ -275,6 +575,45 @@ func (s *mongoPersistent) toBsonFilter(f Filter) bson.M {
 			},
 		})
 	}
+	if f.LeaseExpiredBefore != nil {
+		pipeQuery = append(pipeQuery, bson.M{
+			"lease_until": bson.M{
+				"$not": bson.M{"$gte": *f.LeaseExpiredBefore},
+			},
+		})
+	}
+	if f.CreatedAfter != nil {
+		pipeQuery = append(pipeQuery, bson.M{
+			"created_at": bson.M{
+				"$gte": *f.CreatedAfter,
+			},
+		})
+	}
+	if f.CreatedBefore != nil {
+		pipeQuery = append(pipeQuery, bson.M{
+			"created_at": bson.M{
+				"$lt": *f.CreatedBefore,
+			},
+		})
+	}
+	if f.FinishedBefore != nil {
+		// created time of job always before finished time, zero finished time (never executed) is filtered by created time
+		pipeQuery = append(pipeQuery, bson.M{
+			"finished_at": bson.M{
+				"$not": bson.M{"$gte": *f.FinishedBefore},
+			},
+			"created_at": bson.M{
+				"$lt": *f.FinishedBefore,
+			},
+		})
+	}
+	if f.RunAtBefore != nil {
+		pipeQuery = append(pipeQuery, bson.M{
+			"run_at": bson.M{
+				"$lte": *f.RunAtBefore,
+			},
+		})
+	}
 
 	return bson.M{
 		"$and": pipeQuery,
@@ -0,0 +1,219 @@
+package taskqueueworker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestMongoPersistentJobs(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("SaveJobs", func(mt *mtest.T) {
+		s := &mongoPersistent{mt.DB}
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}))
+
+		// all jobs upserted with single unordered bulk write, job without id is given new id
+		jobs := []*Job{{ID: "1", TaskName: "task-one"}, {TaskName: "task-one"}}
+		s.SaveJobs(context.Background(), jobs)
+		assert.NotEmpty(mt, jobs[1].ID)
+
+		started := mt.GetStartedEvent()
+		assert.Equal(mt, "update", started.CommandName)
+		assert.False(mt, started.Command.Lookup("ordered").Boolean())
+		updates := started.Command.Lookup("updates").Array()
+		first := updates.Index(0).Value().Document()
+		assert.Equal(mt, "1", first.Lookup("q", "_id").StringValue())
+		assert.True(mt, first.Lookup("upsert").Boolean())
+		assert.Equal(mt, jobs[1].ID, updates.Index(1).Value().Document().Lookup("q", "_id").StringValue())
+
+		s.SaveJobs(context.Background(), nil)
+		assert.Nil(mt, mt.GetStartedEvent())
+	})
+
+	mt.Run("DeleteJobs", func(mt *mtest.T) {
+		s := &mongoPersistent{mt.DB}
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}), mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}))
+		assert.NoError(mt, s.DeleteJobs(context.Background(), []string{"1", "2"}))
+
+		// unique keys of deleted jobs deleted before jobs
+		started := mt.GetStartedEvent()
+		assert.Equal(mt, mongoUniqueKeyColl, started.Command.Lookup("delete").StringValue())
+		jobIDs := started.Command.Lookup("deletes").Array().Index(0).Value().Document().Lookup("q", "job_id", "$in").Array()
+		assert.Equal(mt, "2", jobIDs.Index(1).Value().StringValue())
+		started = mt.GetStartedEvent()
+		assert.Equal(mt, mongoColl, started.Command.Lookup("delete").StringValue())
+		ids := started.Command.Lookup("deletes").Array().Index(0).Value().Document().Lookup("q", "_id", "$in").Array()
+		assert.Equal(mt, "2", ids.Index(1).Value().StringValue())
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "failed"}))
+		assert.Error(mt, s.DeleteJobs(context.Background(), []string{"1"}))
+	})
+
+	mt.Run("CleanJob", func(mt *mtest.T) {
+		s := &mongoPersistent{mt.DB}
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "db."+mongoColl, mtest.FirstBatch, bson.D{{Key: "_id", Value: "1"}}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}), mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateCursorResponse(0, "db."+mongoColl, mtest.FirstBatch),
+		)
+		s.CleanJob(context.Background(), "task-one")
+
+		// finished jobs deleted by id until no job left, with their unique keys
+		find := mt.GetStartedEvent().Command
+		assert.Equal(mt, "task-one", find.Lookup("filter", "$and").Array().Index(0).Value().Document().Lookup("task_name").StringValue())
+		assert.Equal(mt, mongoUniqueKeyColl, mt.GetStartedEvent().Command.Lookup("delete").StringValue())
+		started := mt.GetStartedEvent()
+		assert.Equal(mt, mongoColl, started.Command.Lookup("delete").StringValue())
+		ids := started.Command.Lookup("deletes").Array().Index(0).Value().Document().Lookup("q", "_id", "$in").Array()
+		assert.Equal(mt, "1", ids.Index(0).Value().StringValue())
+		assert.Equal(mt, "find", mt.GetStartedEvent().CommandName)
+		assert.Nil(mt, mt.GetStartedEvent())
+	})
+
+	mt.Run("FindAllJob", func(mt *mtest.T) {
+		s := &mongoPersistent{mt.DB}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "db."+mongoColl, mtest.FirstBatch,
+			bson.D{
+				{Key: "_id", Value: "child"}, {Key: "task_name", Value: "task-two"}, {Key: "batch_id", Value: "batch"},
+				{Key: "workflow_id", Value: "root"}, {Key: "parent_id", Value: "root"}, {Key: "child_ids", Value: bson.A{"grandchild"}},
+				{Key: "next_steps", Value: bson.A{bson.D{{Key: "task_name", Value: "task-three"}}}},
+			},
+		))
+
+		// jobs in batch, workflow fields decoded
+		jobs := s.FindAllJob(context.Background(), Filter{BatchID: "batch", Page: 1, Limit: 10})
+		assert.Len(mt, jobs, 1)
+		assert.Equal(mt, "root", jobs[0].WorkflowID)
+		assert.Equal(mt, []string{"grandchild"}, jobs[0].ChildIDs)
+		assert.Equal(mt, "task-three", jobs[0].NextSteps[0].TaskName)
+
+		find := mt.GetStartedEvent().Command
+		assert.Equal(mt, "batch", find.Lookup("filter", "$and").Array().Index(0).Value().Document().Lookup("batch_id").StringValue())
+		assert.Equal(mt, int64(10), find.Lookup("limit").Int64())
+	})
+}
+
+func TestMongoPersistentSaveUniqueJob(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	createdAt := time.Date(2021, time.January, 31, 10, 0, 0, 0, time.UTC)
+	createdAfter := createdAt.Add(-time.Hour)
+	duplicateKeyResponse := mtest.CreateWriteErrorsResponse(mtest.WriteError{Code: 11000, Message: "duplicate key error"})
+	uniqueKeyResponse := mtest.CreateCursorResponse(0, "db."+mongoUniqueKeyColl, mtest.FirstBatch,
+		bson.D{{Key: "task_name", Value: "task-one"}, {Key: "unique_key", Value: "key"}, {Key: "job_id", Value: "old"}})
+
+	mt.Run("unique key is free", func(mt *mtest.T) {
+		s := &mongoPersistent{mt.DB}
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}), mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+
+		job := &Job{ID: "new", TaskName: "task-one", UniqueKey: "key", CreatedAt: createdAt}
+		existingJob, err := s.SaveUniqueJob(context.Background(), job, createdAfter)
+		assert.NoError(mt, err)
+		assert.Nil(mt, existingJob)
+
+		// upsert unique key used by job created before given time, then save job
+		started := mt.GetStartedEvent()
+		assert.Equal(mt, mongoUniqueKeyColl, started.Command.Lookup("update").StringValue())
+		update := started.Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(mt, createdAfter, update.Lookup("q", "created_at", "$lt").Time().UTC())
+		assert.True(mt, update.Lookup("upsert").Boolean())
+		assert.Equal(mt, mongoColl, mt.GetStartedEvent().Command.Lookup("update").StringValue())
+	})
+
+	mt.Run("unique key used by existing job", func(mt *mtest.T) {
+		s := &mongoPersistent{mt.DB}
+		mt.AddMockResponses(duplicateKeyResponse, uniqueKeyResponse, mtest.CreateCursorResponse(0, "db."+mongoColl, mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: "old"}, {Key: "task_name", Value: "task-one"}, {Key: "unique_key", Value: "key"}}))
+
+		job := &Job{ID: "new", TaskName: "task-one", UniqueKey: "key", CreatedAt: createdAt}
+		existingJob, err := s.SaveUniqueJob(context.Background(), job, createdAfter)
+		assert.NoError(mt, err)
+		assert.Equal(mt, "old", existingJob.ID)
+	})
+
+	mt.Run("existing job deleted and unique key taken by another worker", func(mt *mtest.T) {
+		s := &mongoPersistent{mt.DB}
+		mt.AddMockResponses(duplicateKeyResponse, uniqueKeyResponse,
+			mtest.CreateCursorResponse(0, "db."+mongoColl, mtest.FirstBatch),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}))
+
+		job := &Job{ID: "new", TaskName: "task-one", UniqueKey: "key", CreatedAt: createdAt}
+		existingJob, err := s.SaveUniqueJob(context.Background(), job, createdAfter)
+		assert.Equal(mt, errUniqueKeyTaken, err)
+		assert.Nil(mt, existingJob)
+
+		// take over only if still used by deleted job
+		mt.GetStartedEvent()
+		mt.GetStartedEvent()
+		mt.GetStartedEvent()
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(mt, "old", update.Lookup("q", "job_id").StringValue())
+	})
+}
+
+func TestMongoPersistentBatch(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("SaveBatch", func(mt *mtest.T) {
+		s := &mongoPersistent{mt.DB}
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+		s.SaveBatch(context.Background(), &Batch{ID: "batch", TaskName: "task-one", TotalJobs: 2})
+
+		started := mt.GetStartedEvent()
+		assert.Equal(mt, mongoBatchColl, started.Command.Lookup("update").StringValue())
+		update := started.Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(mt, "batch", update.Lookup("q", "_id").StringValue())
+		assert.True(mt, update.Lookup("upsert").Boolean())
+	})
+
+	mt.Run("FindBatchByID", func(mt *mtest.T) {
+		s := &mongoPersistent{mt.DB}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "db."+mongoBatchColl, mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: "batch"}, {Key: "task_name", Value: "task-one"}, {Key: "total_jobs", Value: 2},
+				{Key: "callback_task_name", Value: "callback"}}))
+		batch, err := s.FindBatchByID(context.Background(), "batch")
+		assert.NoError(mt, err)
+		assert.Equal(mt, 2, batch.TotalJobs)
+		assert.Equal(mt, "callback", batch.CallbackTaskName)
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "db."+mongoBatchColl, mtest.FirstBatch))
+		_, err = s.FindBatchByID(context.Background(), "unknown")
+		assert.Equal(mt, mongo.ErrNoDocuments, err)
+	})
+}
+
+func TestMongoPersistentUpdateRecurringJobNextRun(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("UpdateRecurringJobNextRun", func(mt *mtest.T) {
+		s := &mongoPersistent{mt.DB}
+		prevNextRunAt := time.Date(2021, time.January, 31, 10, 0, 0, 0, time.UTC)
+		recurringJob := &RecurringJob{ID: "recurring", NextRunAt: prevNextRunAt.Add(time.Hour), LastRunAt: prevNextRunAt, LastJobID: "job"}
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}), mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}))
+
+		updated, err := s.UpdateRecurringJobNextRun(context.Background(), recurringJob, prevNextRunAt)
+		assert.NoError(mt, err)
+		assert.True(mt, updated)
+
+		// only next run, last run, and last job ID updated if next run not changed
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(mt, prevNextRunAt, update.Lookup("q", "next_run_at").Time().UTC())
+		fields, _ := update.Lookup("u", "$set").Document().Elements()
+		assert.Len(mt, fields, 3)
+		assert.Equal(mt, "job", update.Lookup("u", "$set", "last_job_id").StringValue())
+
+		updated, err = s.UpdateRecurringJobNextRun(context.Background(), recurringJob, prevNextRunAt)
+		assert.NoError(mt, err)
+		assert.False(mt, updated)
+	})
+}
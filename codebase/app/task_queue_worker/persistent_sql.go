@@ -0,0 +1,715 @@
+package taskqueueworker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/golangid/candi/candiutils"
+	"github.com/golangid/candi/logger"
+	"github.com/google/uuid"
+)
+
+const (
+	sqlJobTable   = "task_queue_worker_jobs"
+	sqlBatchTable = "task_queue_worker_batches"
+	sqlRecurTable = "task_queue_worker_recurring_jobs"
+	sqlTaskTable  = "task_queue_worker_tasks"
+
+	// sqlUniqueKeyTable unique key of job with unique key option, guarded by unique index
+	sqlUniqueKeyTable = "task_queue_worker_job_unique_keys"
+
+	// sqlMaxPlaceholders max bind parameters in one statement (postgres limit is 65535)
+	sqlMaxPlaceholders = 60000
+)
+
+type sqlPersistent struct {
+	db *sql.DB
+	*candiutils.SQLDialect
+}
+
+// NewSQLPersistent create sql database persistent, support postgres and mysql (mysql DSN must contains "parseTime=true")
+func NewSQLPersistent(db *sql.DB) Persistent {
+	s := &sqlPersistent{db: db, SQLDialect: candiutils.NewSQLDialect(db)}
+
+	s.createTable(sqlJobTable, s.jobColumns())
+	s.createIndex(sqlJobTable, "task_name")
+	s.createIndex(sqlJobTable, "status")
+	s.createIndex(sqlJobTable, "created_at")
+	s.createIndex(sqlJobTable, "task_name", "status")
+	s.createIndex(sqlJobTable, "status", "run_at")
+	s.createIndex(sqlJobTable, "status", "lease_until")
+	s.createIndex(sqlJobTable, "batch_id", "status")
+
+	s.createTable(sqlBatchTable, s.batchColumns())
+	s.createTable(sqlRecurTable, s.recurringJobColumns())
+	s.createTable(sqlTaskTable, s.taskColumns())
+	s.createTable(sqlUniqueKeyTable, s.uniqueKeyColumns())
+	s.createUniqueIndex(sqlUniqueKeyTable, "task_name", "unique_key")
+	return s
+}
+
+func (s *sqlPersistent) FindAllJob(ctx context.Context, filter Filter) (jobs []Job) {
+	where, args := s.toQueryFilter(filter)
+	query := "SELECT " + s.selectJobColumns() + " FROM " + sqlJobTable + where + " ORDER BY created_at DESC"
+	if !filter.ShowAll {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, s.Rebind(query), args...)
+	if err != nil {
+		logger.LogE(err.Error())
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		job, err := s.scanJob(rows)
+		if err != nil {
+			logger.LogE(err.Error())
+			continue
+		}
+		jobs = append(jobs, *job)
+	}
+
+	return
+}
+
+func (s *sqlPersistent) FindJobByID(ctx context.Context, id string) (job *Job, err error) {
+	query := "SELECT " + s.selectJobColumns() + " FROM " + sqlJobTable + " WHERE id = ?"
+	return s.scanJob(s.db.QueryRowContext(ctx, s.Rebind(query), id))
+}
+
+func (s *sqlPersistent) CountAllJob(ctx context.Context, filter Filter) (count int) {
+	where, args := s.toQueryFilter(filter)
+	query := "SELECT COUNT(*) FROM " + sqlJobTable + where
+	if err := s.db.QueryRowContext(ctx, s.Rebind(query), args...).Scan(&count); err != nil {
+		logger.LogE(err.Error())
+	}
+	return
+}
+
+func (s *sqlPersistent) AggregateAllTaskJob(ctx context.Context, filter Filter) (result []TaskResolver) {
+	where, args := s.toQueryFilter(filter)
+	query := `SELECT task_name,
+		SUM(CASE WHEN status = ? THEN 1 ELSE 0 END),
+		SUM(CASE WHEN status = ? THEN 1 ELSE 0 END),
+		SUM(CASE WHEN status = ? THEN 1 ELSE 0 END),
+		SUM(CASE WHEN status = ? THEN 1 ELSE 0 END),
+		SUM(CASE WHEN status = ? THEN 1 ELSE 0 END),
+		SUM(CASE WHEN status = ? THEN 1 ELSE 0 END)
+		FROM ` + sqlJobTable + where + " GROUP BY task_name"
+	args = append([]interface{}{
+		statusSuccess, statusQueueing, statusRetrying, statusFailure, statusStopped, statusScheduled,
+	}, args...)
+
+	rows, err := s.db.QueryContext(ctx, s.Rebind(query), args...)
+	if err != nil {
+		logger.LogE(err.Error())
+		return
+	}
+	defer rows.Close()
+
+	result = make([]TaskResolver, len(filter.TaskNameList))
+	mapper := make(map[string]int, len(filter.TaskNameList))
+	for i, task := range filter.TaskNameList {
+		result[i].Name = task
+		mapper[task] = i
+	}
+
+	for rows.Next() {
+		var res TaskResolver
+		if err := rows.Scan(&res.Name,
+			&res.Detail.Success, &res.Detail.Queueing, &res.Detail.Retrying, &res.Detail.Failure, &res.Detail.Stopped, &res.Detail.Scheduled,
+		); err != nil {
+			logger.LogE(err.Error())
+			continue
+		}
+
+		if idx, ok := mapper[res.Name]; ok {
+			res.TotalJobs = res.Detail.Success + res.Detail.Queueing + res.Detail.Retrying + res.Detail.Failure +
+				res.Detail.Stopped + res.Detail.Scheduled
+			result[idx] = res
+		}
+	}
+
+	return
+}
+
+func (s *sqlPersistent) SaveJob(ctx context.Context, job *Job) {
+	if job.ID == "" {
+		job.ID = uuid.New().String()
+	}
+
+	if err := s.upsert(ctx, sqlJobTable, s.jobColumns(), s.jobValues(job)); err != nil {
+		logger.LogE(err.Error())
+	}
+}
+
+func (s *sqlPersistent) SaveJobs(ctx context.Context, jobs []*Job) {
+	columns := s.jobColumns()
+	// limit rows in one statement, total placeholders must not exceed database limit
+	chunkSize := sqlMaxPlaceholders / len(columns)
+	for start := 0; start < len(jobs); start += chunkSize {
+		end := start + chunkSize
+		if end > len(jobs) {
+			end = len(jobs)
+		}
+
+		rows := make([][]interface{}, 0, end-start)
+		for _, job := range jobs[start:end] {
+			if job.ID == "" {
+				job.ID = uuid.New().String()
+			}
+			rows = append(rows, s.jobValues(job))
+		}
+		if err := s.upsertRows(ctx, sqlJobTable, columns, rows); err != nil {
+			logger.LogE(err.Error())
+		}
+	}
+}
+
+func (s *sqlPersistent) SaveUniqueJob(ctx context.Context, job *Job, createdAfter time.Time) (*Job, error) {
+	// take over unique key used by job created before given time, unique key is not changed if still used
+	args := []interface{}{job.TaskName, job.UniqueKey, job.ID, job.CreatedAt, s.nullTime(createdAfter)}
+	query := "INSERT INTO " + sqlUniqueKeyTable + " (task_name, unique_key, job_id, created_at) VALUES (?, ?, ?, ?)"
+	if s.IsPostgres {
+		query += " ON CONFLICT (task_name, unique_key) DO UPDATE SET job_id = EXCLUDED.job_id, created_at = EXCLUDED.created_at" +
+			" WHERE " + sqlUniqueKeyTable + ".created_at < ?"
+	} else {
+		query += " ON DUPLICATE KEY UPDATE job_id = IF(created_at < ?, VALUES(job_id), job_id)," +
+			" created_at = IF(created_at < ?, VALUES(created_at), created_at)"
+		args = append(args, s.nullTime(createdAfter))
+	}
+	if _, err := s.db.ExecContext(ctx, s.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+
+	var jobID string
+	query = "SELECT job_id FROM " + sqlUniqueKeyTable + " WHERE task_name = ? AND unique_key = ?"
+	if err := s.db.QueryRowContext(ctx, s.Rebind(query), job.TaskName, job.UniqueKey).Scan(&jobID); err != nil {
+		return nil, err
+	}
+	if jobID != job.ID {
+		existingJob, err := s.FindJobByID(ctx, jobID)
+		if err == nil {
+			return existingJob, nil
+		}
+		if err != sql.ErrNoRows {
+			return nil, err
+		}
+
+		// existing job has been deleted, take over unique key if not taken by another worker instance
+		query = "UPDATE " + sqlUniqueKeyTable + " SET job_id = ?, created_at = ? WHERE task_name = ? AND unique_key = ? AND job_id = ?"
+		res, err := s.db.ExecContext(ctx, s.Rebind(query), job.ID, job.CreatedAt, job.TaskName, job.UniqueKey, jobID)
+		if err != nil {
+			return nil, err
+		}
+		if affected, _ := res.RowsAffected(); affected == 0 {
+			return nil, errUniqueKeyTaken
+		}
+	}
+
+	s.SaveJob(ctx, job)
+	return nil, nil
+}
+
+// upsert insert or update (if id exist) row in given table
+func (s *sqlPersistent) upsert(ctx context.Context, table string, columns []candiutils.SQLColumn, values []interface{}) error {
+	return s.upsertRows(ctx, table, columns, [][]interface{}{values})
+}
+
+// upsertRows insert or update (if id exist) multiple rows in given table with single statement
+func (s *sqlPersistent) upsertRows(ctx context.Context, table string, columns []candiutils.SQLColumn, rows [][]interface{}) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(columns))
+	placeholders := make([]string, len(columns))
+	updates := make([]string, 0, len(columns))
+	for i, col := range columns {
+		names[i], placeholders[i] = col.Name, "?"
+		if col.Name == "id" {
+			continue
+		}
+		if s.IsPostgres {
+			updates = append(updates, col.Name+" = EXCLUDED."+col.Name)
+		} else {
+			updates = append(updates, col.Name+" = VALUES("+col.Name+")")
+		}
+	}
+
+	rowPlaceholder := "(" + strings.Join(placeholders, ", ") + ")"
+	rowPlaceholders := make([]string, len(rows))
+	values := make([]interface{}, 0, len(rows)*len(columns))
+	for i, row := range rows {
+		rowPlaceholders[i] = rowPlaceholder
+		values = append(values, row...)
+	}
+
+	query := "INSERT INTO " + table + " (" + strings.Join(names, ", ") + ") VALUES " + strings.Join(rowPlaceholders, ", ")
+	if s.IsPostgres {
+		query += " ON CONFLICT (id) DO UPDATE SET " + strings.Join(updates, ", ")
+	} else {
+		query += " ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", ")
+	}
+
+	_, err := s.db.ExecContext(ctx, s.Rebind(query), values...)
+	return err
+}
+
+func (s *sqlPersistent) UpdateAllStatus(ctx context.Context, taskName string, currentStatus []JobStatusEnum, updatedStatus JobStatusEnum) {
+	if len(currentStatus) == 0 {
+		return
+	}
+
+	args := []interface{}{updatedStatus}
+	query := "UPDATE " + sqlJobTable + " SET status = ?, retries = 0 WHERE status IN (" + s.placeholders(len(currentStatus)) + ")"
+	for _, status := range currentStatus {
+		args = append(args, status)
+	}
+	if taskName != "" {
+		query += " AND task_name = ?"
+		args = append(args, taskName)
+	}
+
+	if _, err := s.db.ExecContext(ctx, s.Rebind(query), args...); err != nil {
+		logger.LogE(err.Error())
+	}
+}
+
+func (s *sqlPersistent) DeleteJobs(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	// unique key still used by deleted job is deleted, unique key taken over by another job is kept
+	query := "DELETE FROM " + sqlUniqueKeyTable + " WHERE job_id IN (" + s.placeholders(len(ids)) + ")"
+	if _, err := s.db.ExecContext(ctx, s.Rebind(query), args...); err != nil {
+		return err
+	}
+	query = "DELETE FROM " + sqlJobTable + " WHERE id IN (" + s.placeholders(len(ids)) + ")"
+	_, err := s.db.ExecContext(ctx, s.Rebind(query), args...)
+	return err
+}
+
+func (s *sqlPersistent) CleanJob(ctx context.Context, taskName string) {
+	where := " WHERE task_name = ? AND status NOT IN (?, ?, ?)"
+	args := []interface{}{taskName, statusRetrying, statusQueueing, statusScheduled}
+
+	query := "DELETE FROM " + sqlUniqueKeyTable + " WHERE job_id IN (SELECT id FROM " + sqlJobTable + where + ")"
+	if _, err := s.db.ExecContext(ctx, s.Rebind(query), args...); err != nil {
+		logger.LogE(err.Error())
+		return
+	}
+	query = "DELETE FROM " + sqlJobTable + where
+	if _, err := s.db.ExecContext(ctx, s.Rebind(query), args...); err != nil {
+		logger.LogE(err.Error())
+	}
+}
+
+func (s *sqlPersistent) ClaimJob(ctx context.Context, jobID, workerID string, leaseUntil time.Time) (*Job, error) {
+	query := "UPDATE " + sqlJobTable + " SET status = ?, worker_id = ?, lease_until = ? " +
+		"WHERE id = ? AND (status = ? OR (status = ? AND (lease_until IS NULL OR lease_until < ?)))"
+	res, err := s.db.ExecContext(ctx, s.Rebind(query),
+		statusRetrying, workerID, leaseUntil, jobID, statusQueueing, statusRetrying, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return nil, errJobNotClaimed
+	}
+	return s.FindJobByID(ctx, jobID)
+}
+
+func (s *sqlPersistent) RenewJobLease(ctx context.Context, jobID, workerID string, leaseUntil time.Time) error {
+	query := "UPDATE " + sqlJobTable + " SET lease_until = ? WHERE id = ? AND worker_id = ? AND status = ?"
+	res, err := s.db.ExecContext(ctx, s.Rebind(query), leaseUntil, jobID, workerID, statusRetrying)
+	if err != nil {
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errJobLeaseLost
+	}
+	return nil
+}
+
+func (s *sqlPersistent) UpdateJobProgress(ctx context.Context, jobID, workerID string, progress int, message string) {
+	query := "UPDATE " + sqlJobTable + " SET progress = ?, progress_message = ? WHERE id = ? AND worker_id = ? AND status = ?"
+	if _, err := s.db.ExecContext(ctx, s.Rebind(query), progress, message, jobID, workerID, statusRetrying); err != nil {
+		logger.LogE(err.Error())
+	}
+}
+
+func (s *sqlPersistent) FindBatchByID(ctx context.Context, id string) (*Batch, error) {
+	var batch Batch
+	var createdAt, finishedAt sql.NullTime
+	query := "SELECT id, task_name, total_jobs, callback_task_name, callback_max_retry, created_at, finished_at FROM " +
+		sqlBatchTable + " WHERE id = ?"
+	if err := s.db.QueryRowContext(ctx, s.Rebind(query), id).Scan(
+		&batch.ID, &batch.TaskName, &batch.TotalJobs, &batch.CallbackTaskName, &batch.CallbackMaxRetry, &createdAt, &finishedAt,
+	); err != nil {
+		return nil, err
+	}
+	batch.CreatedAt, batch.FinishedAt = createdAt.Time, finishedAt.Time
+	return &batch, nil
+}
+
+func (s *sqlPersistent) SaveBatch(ctx context.Context, batch *Batch) {
+	values := []interface{}{
+		batch.ID, batch.TaskName, batch.TotalJobs, batch.CallbackTaskName, batch.CallbackMaxRetry,
+		s.nullTime(batch.CreatedAt), s.nullTime(batch.FinishedAt),
+	}
+	if err := s.upsert(ctx, sqlBatchTable, s.batchColumns(), values); err != nil {
+		logger.LogE(err.Error())
+	}
+}
+
+func (s *sqlPersistent) batchColumns() []candiutils.SQLColumn {
+	return []candiutils.SQLColumn{
+		{Name: "id", DataType: "VARCHAR(255) NOT NULL PRIMARY KEY"},
+		{Name: "task_name", DataType: "VARCHAR(255) NOT NULL DEFAULT ''"},
+		{Name: "total_jobs", DataType: "INTEGER NOT NULL DEFAULT 0"},
+		{Name: "callback_task_name", DataType: "VARCHAR(255) NOT NULL DEFAULT ''"},
+		{Name: "callback_max_retry", DataType: "INTEGER NOT NULL DEFAULT 0"},
+		{Name: "created_at", DataType: s.TimestampType()},
+		{Name: "finished_at", DataType: s.TimestampType()},
+	}
+}
+
+func (s *sqlPersistent) FindAllRecurringJob(ctx context.Context, taskName string) (recurringJobs []RecurringJob) {
+	var args []interface{}
+	query := "SELECT " + s.selectRecurringJobColumns() + " FROM " + sqlRecurTable
+	if taskName != "" {
+		query += " WHERE task_name = ?"
+		args = append(args, taskName)
+	}
+	query += " ORDER BY created_at ASC"
+
+	rows, err := s.db.QueryContext(ctx, s.Rebind(query), args...)
+	if err != nil {
+		logger.LogE(err.Error())
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		recurringJob, err := s.scanRecurringJob(rows)
+		if err != nil {
+			logger.LogE(err.Error())
+			continue
+		}
+		recurringJobs = append(recurringJobs, *recurringJob)
+	}
+	return
+}
+
+func (s *sqlPersistent) FindRecurringJobByID(ctx context.Context, id string) (*RecurringJob, error) {
+	query := "SELECT " + s.selectRecurringJobColumns() + " FROM " + sqlRecurTable + " WHERE id = ?"
+	return s.scanRecurringJob(s.db.QueryRowContext(ctx, s.Rebind(query), id))
+}
+
+func (s *sqlPersistent) SaveRecurringJob(ctx context.Context, recurringJob *RecurringJob) {
+	values := []interface{}{
+		recurringJob.ID, recurringJob.TaskName, recurringJob.Arguments, recurringJob.MaxRetry, recurringJob.Schedule,
+		recurringJob.IsPaused, s.nullTime(recurringJob.NextRunAt), s.nullTime(recurringJob.LastRunAt), recurringJob.LastJobID,
+		s.nullTime(recurringJob.CreatedAt), s.nullTime(recurringJob.UpdatedAt),
+	}
+	if err := s.upsert(ctx, sqlRecurTable, s.recurringJobColumns(), values); err != nil {
+		logger.LogE(err.Error())
+	}
+}
+
+func (s *sqlPersistent) UpdateRecurringJobNextRun(ctx context.Context, recurringJob *RecurringJob, prevNextRunAt time.Time) (bool, error) {
+	query := "UPDATE " + sqlRecurTable + " SET next_run_at = ?, last_run_at = ?, last_job_id = ? WHERE id = ? AND next_run_at = ?"
+	res, err := s.db.ExecContext(ctx, s.Rebind(query), s.nullTime(recurringJob.NextRunAt), s.nullTime(recurringJob.LastRunAt),
+		recurringJob.LastJobID, recurringJob.ID, prevNextRunAt)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
+
+func (s *sqlPersistent) DeleteRecurringJob(ctx context.Context, id string) {
+	query := "DELETE FROM " + sqlRecurTable + " WHERE id = ?"
+	if _, err := s.db.ExecContext(ctx, s.Rebind(query), id); err != nil {
+		logger.LogE(err.Error())
+	}
+}
+
+func (s *sqlPersistent) recurringJobColumns() []candiutils.SQLColumn {
+	return []candiutils.SQLColumn{
+		{Name: "id", DataType: "VARCHAR(255) NOT NULL PRIMARY KEY"},
+		{Name: "task_name", DataType: "VARCHAR(255) NOT NULL DEFAULT ''"},
+		{Name: "arguments", DataType: "TEXT"},
+		{Name: "max_retry", DataType: "INTEGER NOT NULL DEFAULT 0"},
+		{Name: "schedule", DataType: "VARCHAR(255) NOT NULL DEFAULT ''"},
+		{Name: "is_paused", DataType: "BOOLEAN NOT NULL DEFAULT FALSE"},
+		{Name: "next_run_at", DataType: s.TimestampType()},
+		{Name: "last_run_at", DataType: s.TimestampType()},
+		{Name: "last_job_id", DataType: "VARCHAR(255) NOT NULL DEFAULT ''"},
+		{Name: "created_at", DataType: s.TimestampType()},
+		{Name: "updated_at", DataType: s.TimestampType()},
+	}
+}
+
+func (s *sqlPersistent) selectRecurringJobColumns() string {
+	return "id, task_name, COALESCE(arguments, ''), max_retry, schedule, is_paused, next_run_at, last_run_at, last_job_id, " +
+		"created_at, updated_at"
+}
+
+func (s *sqlPersistent) scanRecurringJob(row interface {
+	Scan(dest ...interface{}) error
+}) (*RecurringJob, error) {
+	var recurringJob RecurringJob
+	var nextRunAt, lastRunAt, createdAt, updatedAt sql.NullTime
+	if err := row.Scan(
+		&recurringJob.ID, &recurringJob.TaskName, &recurringJob.Arguments, &recurringJob.MaxRetry, &recurringJob.Schedule,
+		&recurringJob.IsPaused, &nextRunAt, &lastRunAt, &recurringJob.LastJobID, &createdAt, &updatedAt,
+	); err != nil {
+		return nil, err
+	}
+	recurringJob.NextRunAt, recurringJob.LastRunAt = nextRunAt.Time, lastRunAt.Time
+	recurringJob.CreatedAt, recurringJob.UpdatedAt = createdAt.Time, updatedAt.Time
+	return &recurringJob, nil
+}
+
+func (s *sqlPersistent) FindAllPausedTask(ctx context.Context) (taskNames []string) {
+	query := "SELECT id FROM " + sqlTaskTable + " WHERE is_paused = ? ORDER BY id ASC"
+	rows, err := s.db.QueryContext(ctx, s.Rebind(query), true)
+	if err != nil {
+		logger.LogE(err.Error())
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskName string
+		if err := rows.Scan(&taskName); err != nil {
+			logger.LogE(err.Error())
+			continue
+		}
+		taskNames = append(taskNames, taskName)
+	}
+	return
+}
+
+func (s *sqlPersistent) SetTaskPaused(ctx context.Context, taskName string, isPaused bool) {
+	values := []interface{}{taskName, isPaused, time.Now()}
+	if err := s.upsert(ctx, sqlTaskTable, s.taskColumns(), values); err != nil {
+		logger.LogE(err.Error())
+	}
+}
+
+// taskColumns columns of task state table, id is task name
+func (s *sqlPersistent) taskColumns() []candiutils.SQLColumn {
+	return []candiutils.SQLColumn{
+		{Name: "id", DataType: "VARCHAR(255) NOT NULL PRIMARY KEY"},
+		{Name: "is_paused", DataType: "BOOLEAN NOT NULL DEFAULT FALSE"},
+		{Name: "updated_at", DataType: s.TimestampType()},
+	}
+}
+
+// uniqueKeyColumns columns of job unique key table, unique index of task name & unique key is created separately
+func (s *sqlPersistent) uniqueKeyColumns() []candiutils.SQLColumn {
+	return []candiutils.SQLColumn{
+		{Name: "task_name", DataType: "VARCHAR(255) NOT NULL"},
+		{Name: "unique_key", DataType: "VARCHAR(255) NOT NULL"},
+		{Name: "job_id", DataType: "VARCHAR(255) NOT NULL"},
+		{Name: "created_at", DataType: s.TimestampType()},
+	}
+}
+
+func (s *sqlPersistent) jobColumns() []candiutils.SQLColumn {
+	return []candiutils.SQLColumn{
+		{Name: "id", DataType: "VARCHAR(255) NOT NULL PRIMARY KEY"},
+		{Name: "task_name", DataType: "VARCHAR(255) NOT NULL DEFAULT ''"},
+		{Name: "arguments", DataType: "TEXT"},
+		{Name: "retries", DataType: "INTEGER NOT NULL DEFAULT 0"},
+		{Name: "max_retry", DataType: "INTEGER NOT NULL DEFAULT 0"},
+		{Name: "retry_interval", DataType: "VARCHAR(255) NOT NULL DEFAULT ''"},
+		{Name: "created_at", DataType: s.TimestampType()},
+		{Name: "finished_at", DataType: s.TimestampType()},
+		{Name: "status", DataType: "VARCHAR(255) NOT NULL DEFAULT ''"},
+		{Name: "error", DataType: "TEXT"},
+		{Name: "trace_id", DataType: "VARCHAR(255) NOT NULL DEFAULT ''"},
+		{Name: "run_at", DataType: s.TimestampType()},
+		{Name: "next_retry_at", DataType: s.TimestampType()},
+		{Name: "priority", DataType: "INTEGER NOT NULL DEFAULT 0"},
+		{Name: "unique_key", DataType: "VARCHAR(255) NOT NULL DEFAULT ''"},
+		{Name: "worker_id", DataType: "VARCHAR(255) NOT NULL DEFAULT ''"},
+		{Name: "lease_until", DataType: s.TimestampType()},
+		{Name: "timeout", DataType: "VARCHAR(255) NOT NULL DEFAULT ''"},
+		{Name: "histories", DataType: "TEXT"},
+		{Name: "workflow_id", DataType: "VARCHAR(255) NOT NULL DEFAULT ''"},
+		{Name: "parent_id", DataType: "VARCHAR(255) NOT NULL DEFAULT ''"},
+		{Name: "child_ids", DataType: "TEXT"},
+		{Name: "next_steps", DataType: "TEXT"},
+		{Name: "result", DataType: "TEXT"},
+		{Name: "batch_id", DataType: "VARCHAR(255) NOT NULL DEFAULT ''"},
+		{Name: "recurring_job_id", DataType: "VARCHAR(255) NOT NULL DEFAULT ''"},
+		{Name: "progress", DataType: "INTEGER NOT NULL DEFAULT 0"},
+		{Name: "progress_message", DataType: "TEXT"},
+	}
+}
+
+func (s *sqlPersistent) jobValues(job *Job) []interface{} {
+	return []interface{}{
+		job.ID, job.TaskName, job.Arguments, job.Retries, job.MaxRetry, job.Interval,
+		s.nullTime(job.CreatedAt), s.nullTime(job.FinishedAt), job.Status, job.Error, job.TraceID,
+		s.nullTime(job.RunAt), s.nullTime(job.NextRetryAt), job.Priority, job.UniqueKey,
+		job.WorkerID, s.nullTime(job.LeaseUntil), job.Timeout, s.marshalJSON(job.Histories),
+		job.WorkflowID, job.ParentID, s.marshalJSON(job.ChildIDs), s.marshalJSON(job.NextSteps), job.Result,
+		job.BatchID, job.RecurringJobID, job.Progress, job.ProgressMessage,
+	}
+}
+
+func (s *sqlPersistent) selectJobColumns() string {
+	return "id, task_name, COALESCE(arguments, ''), retries, max_retry, retry_interval, " +
+		"created_at, finished_at, status, COALESCE(error, ''), trace_id, run_at, next_retry_at, priority, unique_key, " +
+		"worker_id, lease_until, timeout, COALESCE(histories, ''), " +
+		"workflow_id, parent_id, COALESCE(child_ids, ''), COALESCE(next_steps, ''), COALESCE(result, ''), batch_id, recurring_job_id, " +
+		"progress, COALESCE(progress_message, '')"
+}
+
+func (s *sqlPersistent) scanJob(row interface {
+	Scan(dest ...interface{}) error
+}) (*Job, error) {
+	var job Job
+	var createdAt, finishedAt, runAt, nextRetryAt, leaseUntil sql.NullTime
+	var histories, childIDs, nextSteps string
+	if err := row.Scan(
+		&job.ID, &job.TaskName, &job.Arguments, &job.Retries, &job.MaxRetry, &job.Interval,
+		&createdAt, &finishedAt, &job.Status, &job.Error, &job.TraceID,
+		&runAt, &nextRetryAt, &job.Priority, &job.UniqueKey,
+		&job.WorkerID, &leaseUntil, &job.Timeout, &histories,
+		&job.WorkflowID, &job.ParentID, &childIDs, &nextSteps, &job.Result,
+		&job.BatchID, &job.RecurringJobID, &job.Progress, &job.ProgressMessage,
+	); err != nil {
+		return nil, err
+	}
+	job.CreatedAt, job.FinishedAt, job.RunAt = createdAt.Time, finishedAt.Time, runAt.Time
+	job.NextRetryAt, job.LeaseUntil = nextRetryAt.Time, leaseUntil.Time
+	s.unmarshalJSON(histories, &job.Histories)
+	s.unmarshalJSON(childIDs, &job.ChildIDs)
+	s.unmarshalJSON(nextSteps, &job.NextSteps)
+	return &job, nil
+}
+
+func (s *sqlPersistent) toQueryFilter(f Filter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if f.TaskName != "" {
+		conditions = append(conditions, "task_name = ?")
+		args = append(args, f.TaskName)
+	} else if len(f.TaskNameList) > 0 {
+		conditions = append(conditions, "task_name IN ("+s.placeholders(len(f.TaskNameList))+")")
+		for _, taskName := range f.TaskNameList {
+			args = append(args, taskName)
+		}
+	}
+
+	if f.BatchID != "" {
+		conditions = append(conditions, "batch_id = ?")
+		args = append(args, f.BatchID)
+	}
+	if f.Search != nil && *f.Search != "" {
+		if s.IsPostgres {
+			conditions = append(conditions, "arguments ILIKE ?")
+		} else {
+			conditions = append(conditions, "arguments LIKE ?")
+		}
+		args = append(args, "%"+*f.Search+"%")
+	}
+	if len(f.Status) > 0 {
+		conditions = append(conditions, "status IN ("+s.placeholders(len(f.Status))+")")
+		for _, status := range f.Status {
+			args = append(args, status)
+		}
+	}
+	if f.LeaseExpiredBefore != nil {
+		conditions = append(conditions, "(lease_until IS NULL OR lease_until < ?)")
+		args = append(args, *f.LeaseExpiredBefore)
+	}
+	if f.CreatedAfter != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, *f.CreatedAfter)
+	}
+	if f.CreatedBefore != nil {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, *f.CreatedBefore)
+	}
+	if f.FinishedBefore != nil {
+		// created time of job always before finished time, job never executed is filtered by created time
+		conditions = append(conditions, "(finished_at IS NULL OR finished_at < ?) AND created_at < ?")
+		args = append(args, *f.FinishedBefore, *f.FinishedBefore)
+	}
+	if f.RunAtBefore != nil {
+		conditions = append(conditions, "run_at <= ?")
+		args = append(args, *f.RunAtBefore)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// createTable create table if not exist and add new columns to existing table
+func (s *sqlPersistent) createTable(tableName string, columns []candiutils.SQLColumn) {
+	if err := s.CreateTable(tableName, columns); err != nil {
+		panic(fmt.Errorf("task queue worker: %v", err))
+	}
+}
+
+func (s *sqlPersistent) createIndex(tableName string, columns ...string) {
+	if err := s.CreateIndex(tableName, columns...); err != nil {
+		logger.LogE(err.Error())
+	}
+}
+
+// createUniqueIndex create unique index, can be used as conflict target of upsert.
+// Job deduplication depends on unique index, so worker is not started without it
+func (s *sqlPersistent) createUniqueIndex(tableName string, columns ...string) {
+	if err := s.CreateUniqueIndex(tableName, columns...); err != nil {
+		panic(fmt.Errorf("task queue worker: failed when create unique index of table %s: %v", tableName, err))
+	}
+}
+
+func (s *sqlPersistent) nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
+
+// marshalJSON encode slice field to json text column, empty slice stored as empty string
+func (s *sqlPersistent) marshalJSON(v interface{}) string {
+	if reflect.ValueOf(v).Len() == 0 {
+		return ""
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func (s *sqlPersistent) unmarshalJSON(data string, v interface{}) {
+	if data != "" {
+		json.Unmarshal([]byte(data), v)
+	}
+}
+
+func (s *sqlPersistent) placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
@@ -0,0 +1,219 @@
+package taskqueueworker
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golangid/candi/candiutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLPersistentQueryFilter(t *testing.T) {
+	search := "test"
+	filter := Filter{
+		TaskNameList: []string{"task-one", "task-two"},
+		Search:       &search,
+		Status:       []string{string(statusQueueing)},
+	}
+
+	s := &sqlPersistent{SQLDialect: &candiutils.SQLDialect{IsPostgres: true}}
+	where, args := s.toQueryFilter(filter)
+	assert.Equal(t, " WHERE task_name IN ($1, $2) AND arguments ILIKE $3 AND status IN ($4)", s.Rebind(where))
+	assert.Equal(t, []interface{}{"task-one", "task-two", "%test%", "QUEUEING"}, args)
+
+	s = &sqlPersistent{SQLDialect: &candiutils.SQLDialect{IsPostgres: false}}
+	where, _ = s.toQueryFilter(filter)
+	assert.Equal(t, " WHERE task_name IN (?, ?) AND arguments LIKE ? AND status IN (?)", s.Rebind(where))
+
+	where, args = s.toQueryFilter(Filter{})
+	assert.Equal(t, "", where)
+	assert.Empty(t, args)
+}
+
+func TestSQLPersistentJobs(t *testing.T) {
+	ctx := context.Background()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	s := &sqlPersistent{db: db, SQLDialect: candiutils.NewSQLDialect(db)}
+
+	// all jobs saved with single statement, job without id is given new id
+	jobs := []*Job{{ID: "1", TaskName: "task-one"}, {TaskName: "task-one"}}
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO "+sqlJobTable) + ".*" +
+		regexp.QuoteMeta("($29, $30, ") + ".*" + regexp.QuoteMeta("$56) ON CONFLICT (id) DO UPDATE SET task_name = EXCLUDED.task_name")).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	s.SaveJobs(ctx, jobs)
+	assert.NotEmpty(t, jobs[1].ID)
+	s.SaveJobs(ctx, nil)
+
+	// unique keys of deleted jobs deleted before jobs
+	assert.NoError(t, s.DeleteJobs(ctx, nil))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM "+sqlUniqueKeyTable+" WHERE job_id IN ($1, $2)")).WithArgs("1", "2").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM "+sqlJobTable+" WHERE id IN ($1, $2)")).WithArgs("1", "2").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	assert.NoError(t, s.DeleteJobs(ctx, []string{"1", "2"}))
+	mock.ExpectExec("DELETE FROM " + sqlUniqueKeyTable).WillReturnError(errors.New("failed"))
+	assert.Error(t, s.DeleteJobs(ctx, []string{"1"}))
+
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM "+sqlUniqueKeyTable+" WHERE job_id IN (SELECT id FROM "+sqlJobTable+
+		" WHERE task_name = $1 AND status NOT IN ($2, $3, $4))")).
+		WithArgs("task-one", statusRetrying, statusQueueing, statusScheduled).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM "+sqlJobTable+" WHERE task_name = $1 AND status NOT IN ($2, $3, $4)")).
+		WithArgs("task-one", statusRetrying, statusQueueing, statusScheduled).WillReturnResult(sqlmock.NewResult(0, 2))
+	s.CleanJob(ctx, "task-one")
+
+	// jobs in batch, workflow fields decoded from json text columns
+	workflowJob := Job{
+		ID: "child", TaskName: "task-two", BatchID: "batch", WorkflowID: "root", ParentID: "root", ChildIDs: []string{"grandchild"},
+		NextSteps: []WorkflowStep{{TaskName: "task-three", MaxRetry: 1}}, Result: "result",
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("FROM "+sqlJobTable+" WHERE batch_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3")).
+		WithArgs("batch", 10, 0).
+		WillReturnRows(sqlJobRows(s, workflowJob))
+	result := s.FindAllJob(ctx, Filter{BatchID: "batch", Page: 1, Limit: 10})
+	assert.Equal(t, []Job{workflowJob}, result)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM " + sqlJobTable + " WHERE id = $1")).WithArgs("child").
+		WillReturnRows(sqlJobRows(s, workflowJob))
+	job, err := s.FindJobByID(ctx, "child")
+	assert.NoError(t, err)
+	assert.Equal(t, "root", job.WorkflowID)
+	assert.Equal(t, []string{"grandchild"}, job.ChildIDs)
+	assert.Equal(t, "task-three", job.NextSteps[0].TaskName)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLPersistentSaveUniqueJob(t *testing.T) {
+	ctx := context.Background()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	s := &sqlPersistent{db: db, SQLDialect: candiutils.NewSQLDialect(db)}
+
+	createdAt := time.Date(2021, time.January, 31, 10, 0, 0, 0, time.UTC)
+	createdAfter := createdAt.Add(-time.Hour)
+	job := &Job{ID: "new", TaskName: "task-one", UniqueKey: "key", CreatedAt: createdAt}
+	expectTakeUniqueKey := func(usedJobID string) {
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO "+sqlUniqueKeyTable+" (task_name, unique_key, job_id, created_at) VALUES ($1, $2, $3, $4) "+
+			"ON CONFLICT (task_name, unique_key) DO UPDATE SET job_id = EXCLUDED.job_id, created_at = EXCLUDED.created_at "+
+			"WHERE "+sqlUniqueKeyTable+".created_at < $5")).
+			WithArgs("task-one", "key", "new", createdAt, createdAfter).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT job_id FROM "+sqlUniqueKeyTable+" WHERE task_name = $1 AND unique_key = $2")).
+			WithArgs("task-one", "key").
+			WillReturnRows(sqlmock.NewRows([]string{"job_id"}).AddRow(usedJobID))
+	}
+
+	// unique key is free, new job saved
+	expectTakeUniqueKey("new")
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO " + sqlJobTable)).WillReturnResult(sqlmock.NewResult(0, 1))
+	existingJob, err := s.SaveUniqueJob(ctx, job, createdAfter)
+	assert.NoError(t, err)
+	assert.Nil(t, existingJob)
+
+	// unique key still used by existing job
+	expectTakeUniqueKey("old")
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE id = $1")).WithArgs("old").
+		WillReturnRows(sqlJobRows(s, Job{ID: "old", TaskName: "task-one", UniqueKey: "key"}))
+	existingJob, err = s.SaveUniqueJob(ctx, job, createdAfter)
+	assert.NoError(t, err)
+	assert.Equal(t, "old", existingJob.ID)
+
+	// existing job has been deleted, unique key taken over by another worker instance
+	expectTakeUniqueKey("old")
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE id = $1")).WithArgs("old").WillReturnRows(sqlJobRows(s))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE "+sqlUniqueKeyTable+" SET job_id = $1, created_at = $2 "+
+		"WHERE task_name = $3 AND unique_key = $4 AND job_id = $5")).
+		WithArgs("new", createdAt, "task-one", "key", "old").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	existingJob, err = s.SaveUniqueJob(ctx, job, createdAfter)
+	assert.Equal(t, errUniqueKeyTaken, err)
+	assert.Nil(t, existingJob)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// mysql only update unique key used by job created before given time
+	s = &sqlPersistent{db: db, SQLDialect: &candiutils.SQLDialect{DB: db}}
+	mock.ExpectExec(regexp.QuoteMeta("VALUES (?, ?, ?, ?) ON DUPLICATE KEY UPDATE job_id = IF(created_at < ?, VALUES(job_id), job_id)")).
+		WithArgs("task-one", "key", "new", createdAt, createdAfter, createdAfter).
+		WillReturnError(errors.New("failed"))
+	_, err = s.SaveUniqueJob(ctx, job, createdAfter)
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLPersistentBatch(t *testing.T) {
+	ctx := context.Background()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	s := &sqlPersistent{db: db, SQLDialect: candiutils.NewSQLDialect(db)}
+
+	createdAt := time.Date(2021, time.January, 31, 10, 0, 0, 0, time.UTC)
+	batch := &Batch{ID: "batch", TaskName: "task-one", TotalJobs: 2, CallbackTaskName: "callback", CallbackMaxRetry: 1, CreatedAt: createdAt}
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO "+sqlBatchTable+" (id, task_name, total_jobs, callback_task_name, callback_max_retry, created_at, finished_at) "+
+		"VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (id) DO UPDATE SET")).
+		WithArgs("batch", "task-one", 2, "callback", 1, createdAt, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.SaveBatch(ctx, batch)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM " + sqlBatchTable + " WHERE id = $1")).WithArgs("batch").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "task_name", "total_jobs", "callback_task_name", "callback_max_retry", "created_at", "finished_at"}).
+			AddRow("batch", "task-one", 2, "callback", 1, createdAt, nil))
+	result, err := s.FindBatchByID(ctx, "batch")
+	assert.NoError(t, err)
+	assert.Equal(t, batch, result)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM " + sqlBatchTable + " WHERE id = $1")).WithArgs("unknown").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	_, err = s.FindBatchByID(ctx, "unknown")
+	assert.Equal(t, sql.ErrNoRows, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLPersistentUpdateRecurringJobNextRun(t *testing.T) {
+	ctx := context.Background()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	s := &sqlPersistent{db: db, SQLDialect: candiutils.NewSQLDialect(db)}
+
+	prevNextRunAt := time.Date(2021, time.January, 31, 10, 0, 0, 0, time.UTC)
+	recurringJob := &RecurringJob{ID: "recurring", NextRunAt: prevNextRunAt.Add(time.Hour), LastRunAt: prevNextRunAt, LastJobID: "job"}
+	query := regexp.QuoteMeta("UPDATE " + sqlRecurTable + " SET next_run_at = $1, last_run_at = $2, last_job_id = $3 WHERE id = $4 AND next_run_at = $5")
+	mock.ExpectExec(query).WithArgs(recurringJob.NextRunAt, recurringJob.LastRunAt, "job", "recurring", prevNextRunAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	updated, err := s.UpdateRecurringJobNextRun(ctx, recurringJob, prevNextRunAt)
+	assert.NoError(t, err)
+	assert.True(t, updated)
+
+	// next run has been changed
+	mock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(0, 0))
+	updated, err = s.UpdateRecurringJobNextRun(ctx, recurringJob, prevNextRunAt)
+	assert.NoError(t, err)
+	assert.False(t, updated)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// sqlJobRows result rows of select job columns query, values ordered like jobValues
+func sqlJobRows(s *sqlPersistent, jobs ...Job) *sqlmock.Rows {
+	columns := make([]string, 0, len(s.jobColumns()))
+	for _, col := range s.jobColumns() {
+		columns = append(columns, col.Name)
+	}
+	rows := sqlmock.NewRows(columns)
+	for _, job := range jobs {
+		var values []driver.Value
+		for _, value := range s.jobValues(&job) {
+			values = append(values, value)
+		}
+		rows.AddRow(values...)
+	}
+	return rows
+}
@@ -0,0 +1,32 @@
+package taskqueueworker
+
+import (
+	"context"
+)
+
+const maxJobProgress = 100
+
+// ReportJobProgress report progress of running job from handler context, progress is percentage (0-100)
+// and message is optional intermediate information (example: "processed 100 of 1000 rows").
+// Progress is persisted in job and pushed to dashboard subscribers in real time
+func ReportJobProgress(ctx context.Context, progress int, message string) {
+	running, ok := ctx.Value(contextKeyRunningJob).(*runningJob)
+	if !ok {
+		return
+	}
+
+	if progress < 0 {
+		progress = 0
+	} else if progress > maxJobProgress {
+		progress = maxJobProgress
+	}
+
+	running.mu.Lock()
+	running.progress, running.progressMessage = progress, message
+	running.mu.Unlock()
+
+	persistent.UpdateJobProgress(context.Background(), running.jobID, workerID, progress, message)
+	if len(clientJobTaskSubscribers) > 0 {
+		go broadcastJobList(context.Background())
+	}
+}
@@ -0,0 +1,34 @@
+package taskqueueworker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportJobProgress(t *testing.T) {
+	ctx := context.Background()
+	setupTestWorker(t)
+	workerID = "worker-one"
+
+	persistent.SaveJob(ctx, &Job{ID: "1", Status: string(statusRetrying), WorkerID: "worker-one"})
+	persistent.SaveJob(ctx, &Job{ID: "2", Status: string(statusRetrying), WorkerID: "worker-two"})
+
+	// no running job in context
+	ReportJobProgress(ctx, 10, "skipped")
+
+	running := &runningJob{jobID: "1"}
+	ReportJobProgress(context.WithValue(ctx, contextKeyRunningJob, running), 150, "processed 10 rows")
+	assert.Equal(t, maxJobProgress, running.progress)
+	job, err := persistent.FindJobByID(ctx, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, maxJobProgress, job.Progress)
+	assert.Equal(t, "processed 10 rows", job.ProgressMessage)
+
+	// job owned by another worker instance is not updated
+	ReportJobProgress(context.WithValue(ctx, contextKeyRunningJob, &runningJob{jobID: "2"}), 50, "")
+	job, err = persistent.FindJobByID(ctx, "2")
+	assert.NoError(t, err)
+	assert.Zero(t, job.Progress)
+}
@@ -3,6 +3,8 @@ package taskqueueworker
 // QueueStorage abstraction for queue storage backend
 type QueueStorage interface {
 	PushJob(job *Job)
+	// PushJobs push multiple jobs in one round trip
+	PushJobs(jobs []*Job)
 	PopJob(taskName string) (jobID string)
 	NextJob(taskName string) (jobID string)
 	Clear(taskName string)
@@ -1,20 +1,33 @@
 package taskqueueworker
 
 import (
+	"container/heap"
 	"sync"
-
-	"github.com/golangid/candi/candishared"
 )
 
-// inMemQueue queue
-type inMemQueue struct {
-	mu    sync.Mutex
-	queue map[string]*candishared.Queue
-}
+type (
+	// inMemQueue queue, job already in queue is not pushed again
+	inMemQueue struct {
+		mu     sync.Mutex
+		seq    uint64
+		queue  map[string]*inMemPriorityQueue
+		queued map[string]struct{}
+	}
+
+	inMemQueueItem struct {
+		jobID    string
+		priority int
+		seq      uint64
+	}
+
+	// inMemPriorityQueue implement heap.Interface, job with higher priority is popped first
+	// and job with same priority is popped in FIFO order
+	inMemPriorityQueue []inMemQueueItem
+)
 
 // NewInMemQueue init inmem queue
 func NewInMemQueue() QueueStorage {
-	q := &inMemQueue{queue: make(map[string]*candishared.Queue)}
+	q := &inMemQueue{queue: make(map[string]*inMemPriorityQueue), queued: make(map[string]struct{})}
 	return q
 }
 
@@ -22,36 +35,78 @@ func (i *inMemQueue) PushJob(job *Job) {
 	i.mu.Lock()
 	defer i.mu.Unlock()
 
-	if i.queue[job.TaskName] == nil {
-		i.queue[job.TaskName] = candishared.NewQueue()
+	i.push(job)
+}
+func (i *inMemQueue) PushJobs(jobs []*Job) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, job := range jobs {
+		i.push(job)
 	}
-	i.queue[job.TaskName].Push(job.ID)
 }
 func (i *inMemQueue) PopJob(taskName string) string {
-	el, err := i.queue[taskName].Pop()
-	if err != nil {
-		return ""
-	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
 
-	id, ok := el.(string)
-	if !ok {
+	q := i.queue[taskName]
+	if q == nil || q.Len() == 0 {
 		return ""
 	}
-	return id
+	jobID := heap.Pop(q).(inMemQueueItem).jobID
+	delete(i.queued, jobID)
+	return jobID
 }
 func (i *inMemQueue) NextJob(taskName string) string {
+	i.mu.Lock()
+	defer i.mu.Unlock()
 
-	el, err := i.queue[taskName].Peek()
-	if err != nil {
-		return ""
-	}
-	id, ok := el.(string)
-	if !ok {
+	q := i.queue[taskName]
+	if q == nil || q.Len() == 0 {
 		return ""
 	}
-	return id
+	return (*q)[0].jobID
 }
 func (i *inMemQueue) Clear(taskName string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
 
+	if q := i.queue[taskName]; q != nil {
+		for _, item := range *q {
+			delete(i.queued, item.jobID)
+		}
+	}
 	i.queue[taskName] = nil
 }
+
+// push job to queue if not already in queue, must called with mutex locked
+func (i *inMemQueue) push(job *Job) {
+	if _, ok := i.queued[job.ID]; ok {
+		return
+	}
+	if i.queue[job.TaskName] == nil {
+		i.queue[job.TaskName] = &inMemPriorityQueue{}
+	}
+	i.seq++
+	i.queued[job.ID] = struct{}{}
+	heap.Push(i.queue[job.TaskName], inMemQueueItem{jobID: job.ID, priority: job.Priority, seq: i.seq})
+}
+
+func (q inMemPriorityQueue) Len() int { return len(q) }
+func (q inMemPriorityQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+func (q inMemPriorityQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *inMemPriorityQueue) Push(x interface{}) {
+	*q = append(*q, x.(inMemQueueItem))
+}
+func (q *inMemPriorityQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	*q = old[:n-1]
+	return item
+}
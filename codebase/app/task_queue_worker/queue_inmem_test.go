@@ -0,0 +1,41 @@
+package taskqueueworker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInMemQueuePriority(t *testing.T) {
+	q := NewInMemQueue()
+	assert.Equal(t, "", q.PopJob("task"))
+
+	q.PushJob(&Job{ID: "1", TaskName: "task", Priority: PriorityNormal})
+	q.PushJob(&Job{ID: "2", TaskName: "task", Priority: PriorityLow})
+	q.PushJob(&Job{ID: "3", TaskName: "task", Priority: PriorityHigh})
+	q.PushJob(&Job{ID: "4", TaskName: "task", Priority: PriorityNormal})
+	q.PushJob(&Job{ID: "5", TaskName: "task", Priority: PriorityHigh})
+
+	assert.Equal(t, "3", q.NextJob("task"))
+	for _, expected := range []string{"3", "5", "1", "4", "2", ""} {
+		assert.Equal(t, expected, q.PopJob("task"))
+	}
+}
+
+func TestInMemQueueSkipQueuedJob(t *testing.T) {
+	q := NewInMemQueue()
+	q.PushJob(&Job{ID: "1", TaskName: "task"})
+	q.PushJobs([]*Job{{ID: "1", TaskName: "task"}, {ID: "2", TaskName: "task"}})
+	q.PushJob(&Job{ID: "1", TaskName: "task"})
+
+	for _, expected := range []string{"1", "2", ""} {
+		assert.Equal(t, expected, q.PopJob("task"))
+	}
+
+	// popped job can be pushed again
+	q.PushJob(&Job{ID: "1", TaskName: "task"})
+	q.Clear("task")
+	q.PushJob(&Job{ID: "2", TaskName: "task"})
+	assert.Equal(t, "2", q.PopJob("task"))
+	assert.Equal(t, "", q.PopJob("task"))
+}
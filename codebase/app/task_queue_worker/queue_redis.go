@@ -1,17 +1,28 @@
 package taskqueueworker
 
 import (
-	"encoding/json"
+	"time"
 
+	"github.com/golangid/candi/logger"
 	"github.com/gomodule/redigo/redis"
 )
 
-// redisQueue queue
+const (
+	// redisPriorityScoreFactor multiplier of job priority in sorted set score,
+	// must greater than unix time in milliseconds so jobs with same priority are ordered by push time
+	redisPriorityScoreFactor = 1e13
+
+	// redisQueueKeyPrefix prefix of sorted set key, old queue stored in list with task name as key is not used anymore
+	// (pending jobs is pushed again from persistent when worker started)
+	redisQueueKeyPrefix = "task_queue_worker:queue:"
+)
+
+// redisQueue queue, using sorted set for each task (ordered by priority and push time)
 type redisQueue struct {
 	pool *redis.Pool
 }
 
-// NewRedisQueue init inmem queue
+// NewRedisQueue init redis queue, require redis version 5.0 or later (ZPOPMIN command)
 func NewRedisQueue(redisPool *redis.Pool) QueueStorage {
 	if redisPool == nil {
 		panic("Task queue backend require redis")
@@ -19,50 +30,83 @@ func NewRedisQueue(redisPool *redis.Pool) QueueStorage {
 	return &redisQueue{pool: redisPool}
 }
 
-func (r *redisQueue) GetAllJobs(taskName string) (jobs []*Job) {
+func (r *redisQueue) GetAllJobs(taskName string) (jobIDs []string) {
 	conn := r.pool.Get()
 	defer conn.Close()
 
-	str, _ := conn.Do("LRANGE", taskName, 0, -1)
-	results, _ := str.([]interface{})
-	for _, result := range results {
-		b, _ := result.([]byte)
-		var job Job
-		json.Unmarshal(b, &job)
-		jobs = append(jobs, &job)
-	}
+	jobIDs, _ = redis.Strings(conn.Do("ZRANGE", r.key(taskName), 0, -1))
 	return
 }
 func (r *redisQueue) PushJob(job *Job) {
 	conn := r.pool.Get()
 	defer conn.Close()
 
-	conn.Do("RPUSH", job.TaskName, job.ID)
+	// NX keep position of job already in queue (e.g. job with expired lease pushed again by other instance)
+	if _, err := conn.Do("ZADD", r.key(job.TaskName), "NX", r.score(job), job.ID); err != nil {
+		logger.LogE("task_queue_worker > push job to redis queue: " + err.Error())
+	}
 }
-func (r *redisQueue) PopJob(taskName string) string {
+func (r *redisQueue) PushJobs(jobs []*Job) {
+	if len(jobs) == 0 {
+		return
+	}
+
 	conn := r.pool.Get()
 	defer conn.Close()
 
-	id, _ := redis.String(conn.Do("LPOP", taskName))
-	return id
+	// one ZADD command for all jobs in same task, sent in single pipeline
+	args := make(map[string]redis.Args)
+	var taskNames []string
+	for _, job := range jobs {
+		if _, ok := args[job.TaskName]; !ok {
+			args[job.TaskName] = redis.Args{r.key(job.TaskName), "NX"}
+			taskNames = append(taskNames, job.TaskName)
+		}
+		args[job.TaskName] = args[job.TaskName].Add(r.score(job), job.ID)
+	}
+	for _, taskName := range taskNames {
+		conn.Send("ZADD", args[taskName]...)
+	}
+	if _, err := conn.Do(""); err != nil {
+		logger.LogE("task_queue_worker > push jobs to redis queue: " + err.Error())
+	}
 }
-func (r *redisQueue) NextJob(taskName string) string {
+func (r *redisQueue) PopJob(taskName string) string {
 	conn := r.pool.Get()
 	defer conn.Close()
 
-	id, err := redis.String(conn.Do("LINDEX", taskName, 0))
+	result, err := redis.Strings(conn.Do("ZPOPMIN", r.key(taskName)))
 	if err != nil {
+		logger.LogE("task_queue_worker > pop job from redis queue: " + err.Error())
+		return ""
+	}
+	if len(result) == 0 {
 		return ""
 	}
+	return result[0]
+}
+func (r *redisQueue) NextJob(taskName string) string {
+	conn := r.pool.Get()
+	defer conn.Close()
 
-	if len(id) == 0 {
+	result, err := redis.Strings(conn.Do("ZRANGE", r.key(taskName), 0, 0))
+	if err != nil || len(result) == 0 {
 		return ""
 	}
-	return id
+	return result[0]
 }
 func (r *redisQueue) Clear(taskName string) {
 	conn := r.pool.Get()
 	defer conn.Close()
 
-	conn.Do("DEL", taskName)
+	conn.Do("DEL", r.key(taskName))
+}
+
+func (r *redisQueue) key(taskName string) string {
+	return redisQueueKeyPrefix + taskName
+}
+
+// score of job in sorted set, ordered by priority then push time
+func (r *redisQueue) score(job *Job) float64 {
+	return float64(-job.Priority)*redisPriorityScoreFactor + float64(time.Now().UnixNano()/int64(time.Millisecond))
 }
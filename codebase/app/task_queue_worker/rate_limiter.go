@@ -0,0 +1,105 @@
+package taskqueueworker
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/gomodule/redigo/redis"
+)
+
+// RateLimiter abstraction for limit job dispatch rate of task (token bucket),
+// use shared storage (like redis) for apply rate limit across multiple worker instance
+type RateLimiter interface {
+	// Take one token from bucket with given key, return duration to wait until token available
+	// (zero if token has been taken)
+	Take(ctx context.Context, key string, limit candishared.RateLimit) (wait time.Duration, err error)
+}
+
+type (
+	inMemRateLimiter struct {
+		mu      sync.Mutex
+		buckets map[string]*tokenBucket
+	}
+
+	tokenBucket struct {
+		tokens     float64
+		lastRefill time.Time
+	}
+)
+
+// NewInMemRateLimiter init in memory rate limiter, rate limit only applied in each worker instance
+func NewInMemRateLimiter() RateLimiter {
+	return &inMemRateLimiter{buckets: make(map[string]*tokenBucket)}
+}
+
+func (i *inMemRateLimiter) Take(ctx context.Context, key string, limit candishared.RateLimit) (time.Duration, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	now := time.Now()
+	bucket, ok := i.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: limit.BucketSize(), lastRefill: now}
+		i.buckets[key] = bucket
+	}
+
+	rate := limit.Rate()
+	bucket.tokens = math.Min(limit.BucketSize(), bucket.tokens+now.Sub(bucket.lastRefill).Seconds()*rate)
+	bucket.lastRefill = now
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return 0, nil
+	}
+	return time.Duration((1 - bucket.tokens) / rate * float64(time.Second)), nil
+}
+
+// redisRateLimitScript token bucket stored in hash, refilled based on redis server time
+// so that bucket is consistent across worker instances with different clock
+var redisRateLimitScript = redis.NewScript(1, `
+local rate = tonumber(ARGV[1])
+local size = tonumber(ARGV[2])
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(bucket[1]) or size
+local ts = tonumber(bucket[2]) or now
+tokens = math.min(size, tokens + (now - ts) * rate)
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+else
+	wait = math.ceil((1 - tokens) / rate)
+end
+redis.call("HMSET", KEYS[1], "tokens", tostring(tokens), "ts", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil(size / rate / 1000) + 1000)
+return wait
+`)
+
+type redisRateLimiter struct {
+	pool *redis.Pool
+}
+
+// NewRedisRateLimiter init redis rate limiter, rate limit is shared across all worker instance
+func NewRedisRateLimiter(redisPool *redis.Pool) RateLimiter {
+	if redisPool == nil {
+		panic("Task queue rate limiter require redis")
+	}
+	return &redisRateLimiter{pool: redisPool}
+}
+
+func (r *redisRateLimiter) Take(ctx context.Context, key string, limit candishared.RateLimit) (time.Duration, error) {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	// rate in tokens per microsecond
+	rate := strconv.FormatFloat(limit.Rate()/1e6, 'g', -1, 64)
+	wait, err := redis.Int64(redisRateLimitScript.Do(conn, "task_queue_worker:rate_limit:"+key, rate, limit.BucketSize()))
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(wait) * time.Microsecond, nil
+}
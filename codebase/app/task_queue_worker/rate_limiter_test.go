@@ -0,0 +1,30 @@
+package taskqueueworker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInMemRateLimiter(t *testing.T) {
+	ctx := context.Background()
+	limiter := NewInMemRateLimiter()
+	limit := candishared.RateLimit{Limit: 2, Interval: time.Second}
+
+	for i := 0; i < 2; i++ {
+		wait, err := limiter.Take(ctx, "task-one", limit)
+		assert.NoError(t, err)
+		assert.Zero(t, wait)
+	}
+
+	wait, err := limiter.Take(ctx, "task-one", limit)
+	assert.NoError(t, err)
+	assert.InDelta(t, float64(500*time.Millisecond), float64(wait), float64(50*time.Millisecond))
+
+	// bucket of each task is independent
+	wait, _ = limiter.Take(ctx, "task-two", limit)
+	assert.Zero(t, wait)
+}
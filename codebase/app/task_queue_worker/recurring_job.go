@@ -0,0 +1,164 @@
+package taskqueueworker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/candiutils"
+	"github.com/golangid/candi/logger"
+)
+
+// RecurringJob model, job template which added as new job periodically based on schedule
+type RecurringJob struct {
+	ID        string `bson:"_id" json:"_id"`
+	TaskName  string `bson:"task_name" json:"task_name"`
+	Arguments string `bson:"arguments" json:"arguments"`
+	MaxRetry  int    `bson:"max_retry" json:"max_retry"`
+	// Schedule cron expression (see candiutils.ParseCronExpression) or interval duration string (example: 5m)
+	Schedule  string    `bson:"schedule" json:"schedule"`
+	IsPaused  bool      `bson:"is_paused" json:"is_paused"`
+	NextRunAt time.Time `bson:"next_run_at" json:"next_run_at"`
+	LastRunAt time.Time `bson:"last_run_at" json:"last_run_at"`
+	LastJobID string    `bson:"last_job_id" json:"last_job_id"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// AddRecurringJob public function for add recurring job with given unique ID, if recurring job with same ID
+// already exist, task name, arguments, max retry, and schedule will be replaced (paused state is kept)
+func AddRecurringJob(id, taskName string, maxRetry int, args []byte, schedule string) error {
+	if id == "" {
+		return errors.New("Recurring job ID cannot empty")
+	}
+	if _, err := validateJob(taskName, maxRetry, addJobOption{}); err != nil {
+		return err
+	}
+	if err := validateJobArgs(taskName, args); err != nil {
+		return err
+	}
+	cronSchedule, err := parseRecurringSchedule(schedule)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+	recurringJob, err := persistent.FindRecurringJobByID(ctx, id)
+	if err != nil {
+		recurringJob = &RecurringJob{ID: id, CreatedAt: now}
+	}
+	if recurringJob.Schedule != schedule || recurringJob.NextRunAt.IsZero() {
+		recurringJob.NextRunAt = cronSchedule.Next(now)
+	}
+	recurringJob.TaskName = taskName
+	recurringJob.Arguments = string(args)
+	recurringJob.MaxRetry = maxRetry
+	recurringJob.Schedule = schedule
+	recurringJob.UpdatedAt = now
+	persistent.SaveRecurringJob(ctx, recurringJob)
+	return nil
+}
+
+// PauseRecurringJob public function for pause recurring job, job will not be added until resumed
+func PauseRecurringJob(id string) error {
+	return updateRecurringJob(id, func(recurringJob *RecurringJob) error {
+		recurringJob.IsPaused = true
+		return nil
+	})
+}
+
+// ResumeRecurringJob public function for resume paused recurring job, next run is calculated from current time
+func ResumeRecurringJob(id string) error {
+	return updateRecurringJob(id, func(recurringJob *RecurringJob) error {
+		cronSchedule, err := parseRecurringSchedule(recurringJob.Schedule)
+		if err != nil {
+			return err
+		}
+		recurringJob.IsPaused = false
+		recurringJob.NextRunAt = cronSchedule.Next(time.Now())
+		return nil
+	})
+}
+
+// DeleteRecurringJob public function for delete recurring job, jobs which already added is not deleted
+func DeleteRecurringJob(id string) error {
+	ctx := context.Background()
+	if _, err := persistent.FindRecurringJobByID(ctx, id); err != nil {
+		return err
+	}
+	persistent.DeleteRecurringJob(ctx, id)
+	return nil
+}
+
+func updateRecurringJob(id string, update func(*RecurringJob) error) error {
+	ctx := context.Background()
+	recurringJob, err := persistent.FindRecurringJobByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := update(recurringJob); err != nil {
+		return err
+	}
+	recurringJob.UpdatedAt = time.Now()
+	persistent.SaveRecurringJob(ctx, recurringJob)
+	return nil
+}
+
+// parseRecurringSchedule parse cron expression or interval duration
+func parseRecurringSchedule(schedule string) (candiutils.CronSchedule, error) {
+	if _, err := time.ParseDuration(schedule); err == nil {
+		schedule = "@every " + schedule
+	}
+	cronSchedule, err := candiutils.ParseCronExpression(schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule: %v", err)
+	}
+	return cronSchedule, nil
+}
+
+// enqueueRecurringJobs add new job from all due recurring jobs
+func (t *taskQueueWorker) enqueueRecurringJobs() {
+	now := time.Now()
+	for _, recurringJob := range persistent.FindAllRecurringJob(t.ctx, "") {
+		if _, ok := registeredTask[recurringJob.TaskName]; !ok || recurringJob.IsPaused || recurringJob.NextRunAt.After(now) {
+			continue
+		}
+
+		cronSchedule, err := parseRecurringSchedule(recurringJob.Schedule)
+		if err != nil {
+			logger.LogE(fmt.Sprintf("task_queue_worker > recurring job %s: %v", recurringJob.ID, err))
+			continue
+		}
+
+		// unique key prevent same run added more than once by multiple worker instance
+		uniqueKey := "recurring:" + recurringJob.ID + ":" + strconv.FormatInt(recurringJob.NextRunAt.Unix(), 10)
+		jobID, err := AddJobWithOption(recurringJob.TaskName, recurringJob.MaxRetry, []byte(recurringJob.Arguments),
+			AddJobOptionUniqueKey(uniqueKey, 0), func(o *addJobOption) { o.recurringJobID = recurringJob.ID })
+		if err != nil {
+			logger.LogE(fmt.Sprintf("task_queue_worker > recurring job %s: %v", recurringJob.ID, err))
+			continue
+		}
+
+		prevNextRunAt := recurringJob.NextRunAt
+		recurringJob.LastRunAt = now
+		recurringJob.LastJobID = jobID
+		// missed runs is skipped, next run is calculated from current time
+		recurringJob.NextRunAt = cronSchedule.Next(now)
+		// only next run is updated, so that concurrent update (like pause or schedule changed) is not overwritten,
+		// not updated if next run has been changed by another worker instance or by schedule update
+		if _, err := persistent.UpdateRecurringJobNextRun(t.ctx, &recurringJob, prevNextRunAt); err != nil {
+			logger.LogE(fmt.Sprintf("task_queue_worker > recurring job %s: %v", recurringJob.ID, err))
+		}
+	}
+}
+
+func (r *RecurringJob) updateValue() {
+	r.NextRunAt = r.NextRunAt.In(candihelper.AsiaJakartaLocalTime)
+	r.LastRunAt = r.LastRunAt.In(candihelper.AsiaJakartaLocalTime)
+	r.CreatedAt = r.CreatedAt.In(candihelper.AsiaJakartaLocalTime)
+	r.UpdatedAt = r.UpdatedAt.In(candihelper.AsiaJakartaLocalTime)
+}
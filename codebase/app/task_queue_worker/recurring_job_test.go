@@ -0,0 +1,80 @@
+package taskqueueworker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecurringJob(t *testing.T) {
+	ctx := context.Background()
+	setupTestWorker(t, types.WorkerHandler{Pattern: "task-one"})
+
+	assert.Error(t, AddRecurringJob("recurring", "task-one", 1, nil, "invalid"))
+	assert.Error(t, AddRecurringJob("recurring", "task-two", 1, nil, "5m"))
+
+	assert.NoError(t, AddRecurringJob("recurring", "task-one", 1, []byte("args"), "5m"))
+	recurringJob, err := persistent.FindRecurringJobByID(ctx, "recurring")
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), recurringJob.NextRunAt, time.Second)
+
+	assert.NoError(t, PauseRecurringJob("recurring"))
+	// paused state is kept when recurring job replaced
+	assert.NoError(t, AddRecurringJob("recurring", "task-one", 1, []byte("args"), "0 0 * * *"))
+	recurringJob, err = persistent.FindRecurringJobByID(ctx, "recurring")
+	assert.NoError(t, err)
+	assert.True(t, recurringJob.IsPaused)
+	assert.Equal(t, 0, recurringJob.NextRunAt.Hour())
+
+	assert.NoError(t, ResumeRecurringJob("recurring"))
+	assert.Len(t, persistent.FindAllRecurringJob(ctx, "task-one"), 1)
+
+	assert.NoError(t, DeleteRecurringJob("recurring"))
+	assert.Error(t, DeleteRecurringJob("recurring"))
+}
+
+func TestEnqueueRecurringJobs(t *testing.T) {
+	ctx := context.Background()
+	setupTestWorker(t, types.WorkerHandler{Pattern: "task-one"})
+	worker := newTestWorker(t)
+
+	persistent.SaveRecurringJob(ctx, &RecurringJob{ID: "recurring", TaskName: "task-one", MaxRetry: 1, Arguments: "args",
+		Schedule: "5m", NextRunAt: time.Now().Add(-time.Minute), CreatedAt: time.Now()})
+	worker.enqueueRecurringJobs()
+	select {
+	case <-refreshWorkerNotif:
+	case <-time.After(time.Second):
+		t.Fatal("recurring job not notified to worker")
+	}
+
+	jobs := persistent.FindAllJob(ctx, Filter{TaskName: "task-one", ShowAll: true})
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, "recurring", jobs[0].RecurringJobID)
+	recurringJob, err := persistent.FindRecurringJobByID(ctx, "recurring")
+	assert.NoError(t, err)
+	assert.Equal(t, jobs[0].ID, recurringJob.LastJobID)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), recurringJob.NextRunAt, time.Second)
+
+	// recurring job not added again before next run
+	worker.enqueueRecurringJobs()
+	assert.Equal(t, 1, persistent.CountAllJob(ctx, Filter{TaskName: "task-one"}))
+
+	// only next run updated, paused state changed after recurring job found is kept
+	prevNextRunAt := recurringJob.NextRunAt
+	assert.NoError(t, PauseRecurringJob("recurring"))
+	recurringJob.NextRunAt = prevNextRunAt.Add(5 * time.Minute)
+	updated, err := persistent.UpdateRecurringJobNextRun(ctx, recurringJob, prevNextRunAt)
+	assert.NoError(t, err)
+	assert.True(t, updated)
+	saved, _ := persistent.FindRecurringJobByID(ctx, "recurring")
+	assert.True(t, saved.IsPaused)
+	assert.Equal(t, recurringJob.NextRunAt, saved.NextRunAt)
+
+	// not updated when next run has been changed by another worker instance
+	updated, err = persistent.UpdateRecurringJobNextRun(ctx, recurringJob, prevNextRunAt)
+	assert.NoError(t, err)
+	assert.False(t, updated)
+}
@@ -0,0 +1,130 @@
+package taskqueueworker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golangid/candi/logger"
+)
+
+const retentionSweepLimit = 500
+
+type (
+	// RetentionPolicy model, finished job with given status will be deleted after max age
+	// (since job finished, or since job created if job never executed)
+	RetentionPolicy struct {
+		// TaskName apply policy only to given task, empty means apply to all tasks
+		// (policy with task name take precedence over policy without task name for the same status)
+		TaskName string
+		// Status final status of job (SUCCESS, FAILURE, or STOPPED)
+		Status string
+		MaxAge time.Duration
+	}
+
+	// JobArchiver abstraction for export old jobs before deleted by retention sweeper
+	JobArchiver interface {
+		// ArchiveJobs export given jobs, jobs will not be deleted if return error
+		ArchiveJobs(ctx context.Context, jobs []Job) error
+	}
+)
+
+// runRetentionSweeper periodically delete (and archive if archiver is set) old jobs based on retention policies
+func (t *taskQueueWorker) runRetentionSweeper() {
+	if len(defaultOption.RetentionPolicies) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(defaultOption.RetentionSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		t.sweepExpiredJobs()
+
+		select {
+		case <-t.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (t *taskQueueWorker) sweepExpiredJobs() {
+	var totalDeleted int
+	for _, filter := range retentionFilters(defaultOption.RetentionPolicies, tasks, time.Now()) {
+		deleted, err := sweepJobs(t.ctx, filter)
+		totalDeleted += deleted
+		if err != nil {
+			logger.LogE(fmt.Sprintf("task_queue_worker > retention sweeper: %v", err))
+		}
+	}
+
+	if totalDeleted > 0 {
+		broadcastAllToSubscribers(t.ctx)
+	}
+}
+
+// retentionFilters build job filter from each retention policy, policy without task name only applied to tasks
+// which not have own policy for the same status
+func retentionFilters(policies []RetentionPolicy, taskNames []string, now time.Time) (filters []Filter) {
+	taskPolicies := make(map[string]bool)
+	for _, policy := range policies {
+		if policy.TaskName != "" {
+			taskPolicies[policy.Status+":"+policy.TaskName] = true
+		}
+	}
+
+	for _, policy := range policies {
+		finishedBefore := now.Add(-policy.MaxAge)
+		filter := Filter{
+			Status:         []string{policy.Status},
+			FinishedBefore: &finishedBefore,
+		}
+
+		if policy.TaskName != "" {
+			filter.TaskName = policy.TaskName
+		} else {
+			for _, taskName := range taskNames {
+				if !taskPolicies[policy.Status+":"+taskName] {
+					filter.TaskNameList = append(filter.TaskNameList, taskName)
+				}
+			}
+			if len(filter.TaskNameList) == 0 {
+				continue
+			}
+		}
+		filters = append(filters, filter)
+	}
+	return
+}
+
+// sweepJobs archive and delete all jobs matched with filter, always find first page because deleted jobs
+// is not matched anymore, stop when failed delete jobs so same jobs is not archived again
+func sweepJobs(ctx context.Context, filter Filter) (totalDeleted int, err error) {
+	filter.Page, filter.Limit = 1, retentionSweepLimit
+	for {
+		jobs := persistent.FindAllJob(ctx, filter)
+		if len(jobs) == 0 {
+			return totalDeleted, nil
+		}
+
+		if defaultOption.JobArchiver != nil {
+			if err := defaultOption.JobArchiver.ArchiveJobs(ctx, jobs); err != nil {
+				return totalDeleted, fmt.Errorf("failed archive jobs: %v", err)
+			}
+		}
+
+		ids := make([]string, len(jobs))
+		for i, job := range jobs {
+			ids[i] = job.ID
+		}
+		if err := persistent.DeleteJobs(ctx, ids); err != nil {
+			return totalDeleted, fmt.Errorf("failed delete jobs: %v", err)
+		}
+		totalDeleted += len(jobs)
+
+		if len(jobs) < filter.Limit {
+			return totalDeleted, nil
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package taskqueueworker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type archiverFunc func(ctx context.Context, jobs []Job) error
+
+func (f archiverFunc) ArchiveJobs(ctx context.Context, jobs []Job) error {
+	return f(ctx, jobs)
+}
+
+type failedDeletePersistent struct{ Persistent }
+
+func (failedDeletePersistent) DeleteJobs(ctx context.Context, ids []string) error {
+	return errors.New("error")
+}
+
+func TestRetentionFilters(t *testing.T) {
+	now := time.Now()
+	filters := retentionFilters([]RetentionPolicy{
+		{Status: string(statusSuccess), MaxAge: time.Hour},
+		{Status: string(statusSuccess), TaskName: "task-one", MaxAge: 2 * time.Hour},
+		{Status: string(statusFailure), TaskName: "task-two", MaxAge: 3 * time.Hour},
+	}, []string{"task-one", "task-two"}, now)
+
+	assert.Len(t, filters, 3)
+	assert.Equal(t, []string{"task-two"}, filters[0].TaskNameList)
+	assert.Equal(t, now.Add(-time.Hour), *filters[0].FinishedBefore)
+	assert.Equal(t, "task-one", filters[1].TaskName)
+	assert.Equal(t, []string{string(statusFailure)}, filters[2].Status)
+}
+
+func TestSweepJobs(t *testing.T) {
+	ctx := context.Background()
+	setupTestWorker(t)
+	defer func() { defaultOption.JobArchiver = nil }()
+
+	old := time.Now().Add(-48 * time.Hour)
+	persistent.SaveJob(ctx, &Job{ID: "1", TaskName: "task-one", Status: string(statusSuccess), CreatedAt: old, FinishedAt: old})
+	persistent.SaveJob(ctx, &Job{ID: "2", TaskName: "task-one", Status: string(statusSuccess), CreatedAt: time.Now()})
+	persistent.SaveJob(ctx, &Job{ID: "3", TaskName: "task-one", Status: string(statusFailure), CreatedAt: old})
+	// long running job is kept until max age since finished
+	persistent.SaveJob(ctx, &Job{ID: "4", TaskName: "task-one", Status: string(statusSuccess), CreatedAt: old, FinishedAt: time.Now()})
+	// stopped job never executed is filtered by created time
+	persistent.SaveJob(ctx, &Job{ID: "5", TaskName: "task-one", Status: string(statusStopped), CreatedAt: old})
+
+	finishedBefore := time.Now().Add(-24 * time.Hour)
+	filter := Filter{TaskName: "task-one", Status: []string{string(statusSuccess), string(statusStopped)}, FinishedBefore: &finishedBefore}
+
+	defaultOption.JobArchiver = archiverFunc(func(ctx context.Context, jobs []Job) error {
+		return errors.New("error")
+	})
+	deleted, err := sweepJobs(ctx, filter)
+	assert.Error(t, err)
+	assert.Equal(t, 0, deleted)
+
+	var archived []Job
+	defaultOption.JobArchiver = archiverFunc(func(ctx context.Context, jobs []Job) error {
+		archived = append(archived, jobs...)
+		return nil
+	})
+	storage := persistent
+	persistent = failedDeletePersistent{storage}
+	deleted, err = sweepJobs(ctx, filter)
+	assert.Error(t, err)
+	assert.Equal(t, 0, deleted)
+	assert.Len(t, archived, 2)
+
+	persistent, archived = storage, nil
+	deleted, err = sweepJobs(ctx, filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+	assert.ElementsMatch(t, []string{"1", "5"}, []string{archived[0].ID, archived[1].ID})
+	assert.Equal(t, 3, persistent.CountAllJob(ctx, Filter{}))
+}
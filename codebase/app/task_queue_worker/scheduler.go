@@ -0,0 +1,106 @@
+package taskqueueworker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golangid/candi/logger"
+)
+
+// runScheduler periodically push all due scheduled jobs and jobs with expired lease to queue
+func (t *taskQueueWorker) runScheduler() {
+	ticker := time.NewTicker(defaultOption.CheckScheduledJobInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.ctx.Done():
+			return
+		case <-ticker.C:
+			t.refreshPausedTasks()
+			t.enqueueScheduledJobs()
+			t.enqueueExpiredLeaseJobs()
+			t.enqueueRecurringJobs()
+		}
+	}
+}
+
+func (t *taskQueueWorker) enqueueScheduledJobs() {
+	now := time.Now()
+	jobs := persistent.FindAllJob(t.ctx, Filter{
+		TaskNameList: tasks,
+		Status:       []string{string(statusScheduled)},
+		RunAtBefore:  &now,
+		ShowAll:      true,
+	})
+	if len(jobs) == 0 {
+		return
+	}
+
+	for _, job := range jobs {
+		job := job
+		// due job (including job waiting for retry) is dispatched in default interval
+		job.Status, job.Interval = string(statusQueueing), defaultInterval
+		persistent.SaveJob(t.ctx, &job)
+		queue.PushJob(&job)
+		registerJobToWorker(&job, registeredTask[job.TaskName].workerIndex)
+	}
+
+	broadcastAllToSubscribers(t.ctx)
+	refreshWorkerNotif <- struct{}{}
+}
+
+// enqueueExpiredLeaseJobs push again running jobs which owner instance has died to queue,
+// job which is still in queue (not claimed yet) is not pushed twice
+func (t *taskQueueWorker) enqueueExpiredLeaseJobs() {
+	now := time.Now()
+	jobs := persistent.FindAllJob(t.ctx, Filter{
+		TaskNameList:       tasks,
+		Status:             []string{string(statusRetrying)},
+		LeaseExpiredBefore: &now,
+		ShowAll:            true,
+	})
+	if len(jobs) == 0 {
+		return
+	}
+
+	for _, job := range jobs {
+		job := job
+		queue.PushJob(&job)
+		registerJobToWorker(&job, registeredTask[job.TaskName].workerIndex)
+	}
+	refreshWorkerNotif <- struct{}{}
+}
+
+// heartbeatJob periodically renew lease of running job until returned stop function called,
+// job context will be canceled if lease has been lost or job has been stopped from another worker instance
+func (t *taskQueueWorker) heartbeatJob(job *Job, running *runningJob) (stop func()) {
+	done := make(chan struct{})
+	leaseDuration := defaultOption.JobLeaseDuration
+	go func() {
+		ticker := time.NewTicker(leaseDuration / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				err := persistent.RenewJobLease(t.ctx, job.ID, workerID, time.Now().Add(leaseDuration))
+				if err == errJobLeaseLost {
+					if current, errFind := persistent.FindJobByID(t.ctx, job.ID); errFind == nil && current.Status == string(statusStopped) {
+						err = errJobStopped
+					}
+					running.stop(err)
+				}
+				if err != nil {
+					logger.LogRed(fmt.Sprintf("task_queue_worker > job %s: %v", job.ID, err))
+				}
+				if running.reason() != nil {
+					return
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
@@ -0,0 +1,80 @@
+package taskqueueworker
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/codebase/factory/types"
+)
+
+// setupTestWorker replace worker global state with file storage (used as persistent and queue) and register given handlers
+// like worker serve do, handler pattern is task name and worker index start from 1. Global state is restored when test finished.
+func setupTestWorker(t *testing.T, handlers ...types.WorkerHandler) FileStorage {
+	prevPersistent, prevQueue, prevTasks, prevOption := persistent, queue, tasks, defaultOption
+	prevRegisteredTask, prevWorkerIndexTask, prevSemaphore := registeredTask, workerIndexTask, semaphore
+	prevWorkers, prevRefreshWorkerNotif, prevShutdown, prevWorkerID := workers, refreshWorkerNotif, shutdown, workerID
+	t.Cleanup(func() {
+		persistent, queue, tasks, defaultOption = prevPersistent, prevQueue, prevTasks, prevOption
+		registeredTask, workerIndexTask, semaphore = prevRegisteredTask, prevWorkerIndexTask, prevSemaphore
+		workers, refreshWorkerNotif, shutdown, workerID = prevWorkers, prevRefreshWorkerNotif, prevShutdown, prevWorkerID
+	})
+
+	storage := NewFileStorage(filepath.Join(t.TempDir(), "task_queue_worker.db"))
+	persistent, queue, tasks, semaphore, workerID = storage, storage, nil, nil, "test-worker"
+	defaultOption.JobLeaseDuration, defaultOption.CheckScheduledJobInterval = time.Minute, time.Second
+	defaultOption.RateLimiter = NewInMemRateLimiter()
+	refreshWorkerNotif, shutdown = make(chan struct{}, 10), make(chan struct{}, 1)
+	workers = []reflect.SelectCase{{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(refreshWorkerNotif)}}
+	registeredTask = make(map[string]struct {
+		handler     types.WorkerHandler
+		workerIndex int
+	})
+	workerIndexTask = make(map[int]*struct {
+		taskName       string
+		activeInterval *time.Ticker
+	})
+	for _, handler := range handlers {
+		if handler.MaxConcurrency <= 0 {
+			handler.MaxConcurrency = 1
+		}
+		workerIndex := len(workers)
+		registeredTask[handler.Pattern] = struct {
+			handler     types.WorkerHandler
+			workerIndex int
+		}{handler: handler, workerIndex: workerIndex}
+		workerIndexTask[workerIndex] = &struct {
+			taskName       string
+			activeInterval *time.Ticker
+		}{taskName: handler.Pattern}
+		tasks = append(tasks, handler.Pattern)
+		workers = append(workers, reflect.SelectCase{Dir: reflect.SelectRecv})
+		semaphore = append(semaphore, make(chan struct{}, handler.MaxConcurrency))
+	}
+	return storage
+}
+
+// newTestWorker create worker instance for execute jobs of registered test handlers, see setupTestWorker
+func newTestWorker(t *testing.T) *taskQueueWorker {
+	worker := &taskQueueWorker{}
+	worker.ctx, worker.ctxCancelFunc = context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		worker.ctxCancelFunc()
+		for _, taskIndex := range workerIndexTask {
+			if taskIndex.activeInterval != nil {
+				taskIndex.activeInterval.Stop()
+			}
+		}
+	})
+	return worker
+}
+
+// queueTestJob save job as queueing job and push to queue of registered test handler
+func queueTestJob(job *Job) {
+	job.Status, job.Interval, job.CreatedAt = string(statusQueueing), defaultInterval, time.Now()
+	persistent.SaveJob(context.Background(), job)
+	queue.PushJob(job)
+	registerJobToWorker(job, registeredTask[job.TaskName].workerIndex)
+}
@@ -67,6 +67,13 @@ func broadcastTaskList(ctx context.Context) {
 
 	var taskRes TaskListResolver
 	taskRes.Data = persistent.AggregateAllTaskJob(ctx, Filter{TaskNameList: tasks})
+	for i := range taskRes.Data {
+		if task, ok := registeredTask[taskRes.Data[i].Name]; ok {
+			taskRes.Data[i].MaxConcurrency = cap(semaphore[task.workerIndex-1])
+			taskRes.Data[i].RunningJobs = len(semaphore[task.workerIndex-1])
+			taskRes.Data[i].IsPaused = IsTaskPaused(taskRes.Data[i].Name)
+		}
+	}
 	taskRes.Meta.TotalClientSubscriber = len(clientTaskSubscribers) + len(clientJobTaskSubscribers)
 
 	for _, subscriber := range clientTaskSubscribers {
@@ -88,6 +95,9 @@ func broadcastJobList(ctx context.Context) {
 	}
 	for _, subscriber := range clientJobTaskSubscribers {
 		jobs := persistent.FindAllJob(ctx, subscriber.filter)
+		for i := range jobs {
+			jobs[i].updateValue()
+		}
 
 		var meta MetaJobList
 		subscriber.filter.TaskNameList = []string{subscriber.filter.TaskName}
@@ -98,6 +108,7 @@ func broadcastJobList(ctx context.Context) {
 			meta.Detail.Success = counterAll[0].Detail.Success
 			meta.Detail.Queueing = counterAll[0].Detail.Queueing
 			meta.Detail.Stopped = counterAll[0].Detail.Stopped
+			meta.Detail.Scheduled = counterAll[0].Detail.Scheduled
 			meta.TotalRecords = counterAll[0].TotalJobs
 		}
 		meta.Page, meta.Limit = subscriber.filter.Page, subscriber.filter.Limit
@@ -0,0 +1,107 @@
+package taskqueueworker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+var (
+	// pausedTasks local state of paused tasks, synced from persistent by scheduler
+	// so that task paused from another worker instance is not dispatched
+	pausedTasks     map[string]bool
+	pausedTaskMutex sync.RWMutex
+)
+
+// PauseTask public function for pause dispatching job in given task, job still can be added and kept in queue
+// until task resumed, running jobs is not stopped
+func PauseTask(taskName string) error {
+	if _, ok := registeredTask[taskName]; !ok {
+		return fmt.Errorf("task '%s' unregistered", taskName)
+	}
+
+	ctx := context.Background()
+	persistent.SetTaskPaused(ctx, taskName, true)
+	setTaskPaused(taskName, true)
+	go broadcastAllToSubscribers(ctx)
+	return nil
+}
+
+// ResumeTask public function for resume dispatching job in given paused task
+func ResumeTask(taskName string) error {
+	task, ok := registeredTask[taskName]
+	if !ok {
+		return fmt.Errorf("task '%s' unregistered", taskName)
+	}
+
+	ctx := context.Background()
+	persistent.SetTaskPaused(ctx, taskName, false)
+	if setTaskPaused(taskName, false) {
+		go func() {
+			broadcastAllToSubscribers(ctx)
+			registerNextJob(taskName, task.workerIndex)
+			refreshWorkerNotif <- struct{}{}
+		}()
+	}
+	return nil
+}
+
+// IsTaskPaused check if given task is paused
+func IsTaskPaused(taskName string) bool {
+	pausedTaskMutex.RLock()
+	defer pausedTaskMutex.RUnlock()
+	return pausedTasks[taskName]
+}
+
+// setTaskPaused set local paused state, return true if state changed
+func setTaskPaused(taskName string, isPaused bool) (changed bool) {
+	pausedTaskMutex.Lock()
+	defer pausedTaskMutex.Unlock()
+
+	if pausedTasks == nil {
+		pausedTasks = make(map[string]bool)
+	}
+	changed = pausedTasks[taskName] != isPaused
+	if isPaused {
+		pausedTasks[taskName] = true
+	} else {
+		delete(pausedTasks, taskName)
+	}
+	return changed
+}
+
+// syncPausedTasks replace local paused state with paused tasks from persistent,
+// return tasks which has been resumed (possibly from another worker instance)
+func syncPausedTasks(ctx context.Context) (resumedTasks []string) {
+	paused := make(map[string]bool)
+	for _, taskName := range persistent.FindAllPausedTask(ctx) {
+		paused[taskName] = true
+	}
+
+	pausedTaskMutex.Lock()
+	defer pausedTaskMutex.Unlock()
+
+	for taskName := range pausedTasks {
+		if !paused[taskName] {
+			resumedTasks = append(resumedTasks, taskName)
+		}
+	}
+	pausedTasks = paused
+	return resumedTasks
+}
+
+// refreshPausedTasks sync paused state and register next job of resumed tasks to worker
+func (t *taskQueueWorker) refreshPausedTasks() {
+	resumedTasks := syncPausedTasks(t.ctx)
+	if len(resumedTasks) == 0 {
+		return
+	}
+
+	for _, taskName := range resumedTasks {
+		if task, ok := registeredTask[taskName]; ok {
+			registerNextJob(taskName, task.workerIndex)
+		}
+	}
+	broadcastAllToSubscribers(t.ctx)
+	refreshWorkerNotif <- struct{}{}
+}
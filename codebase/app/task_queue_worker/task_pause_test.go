@@ -0,0 +1,39 @@
+package taskqueueworker
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPauseTask(t *testing.T) {
+	ctx := context.Background()
+	filePath := filepath.Join(t.TempDir(), "task_queue_worker.db")
+	persistent, queue, refreshWorkerNotif = NewFileStorage(filePath), NewInMemQueue(), make(chan struct{}, 1)
+	registeredTask = map[string]struct {
+		handler     types.WorkerHandler
+		workerIndex int
+	}{"task-one": {}, "task-two": {}}
+	defer func() { persistent, queue, registeredTask, pausedTasks = nil, nil, nil, nil }()
+
+	assert.Error(t, PauseTask("task-three"))
+	assert.NoError(t, PauseTask("task-one"))
+	assert.NoError(t, PauseTask("task-two"))
+	assert.True(t, IsTaskPaused("task-one"))
+
+	assert.NoError(t, ResumeTask("task-one"))
+	assert.False(t, IsTaskPaused("task-one"))
+	<-refreshWorkerNotif
+
+	// paused state is persisted across restart
+	persistent = NewFileStorage(filePath)
+	assert.Equal(t, []string{"task-two"}, persistent.FindAllPausedTask(ctx))
+
+	// task resumed from another worker instance
+	persistent.SetTaskPaused(ctx, "task-two", false)
+	assert.Equal(t, []string{"task-two"}, syncPausedTasks(ctx))
+	assert.False(t, IsTaskPaused("task-two"))
+}
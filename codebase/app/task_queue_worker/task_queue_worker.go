@@ -26,6 +26,33 @@ type taskQueueWorker struct {
 	wg      sync.WaitGroup
 }
 
+// runningJob hold cancel function of job executed in this worker instance
+type runningJob struct {
+	mu         sync.Mutex
+	jobID      string
+	cancel     context.CancelFunc
+	stopReason error
+	result     []byte
+
+	progress        int
+	progressMessage string
+}
+
+func (r *runningJob) stop(reason error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stopReason == nil {
+		r.stopReason = reason
+	}
+	r.cancel()
+}
+
+func (r *runningJob) reason() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stopReason
+}
+
 // NewTaskQueueWorker create new task queue worker
 func NewTaskQueueWorker(service factory.ServiceFactory, q QueueStorage, perst Persistent, opts ...OptionFunc) factory.AppServerFactory {
 	makeAllGlobalVars(q, perst, opts...)
@@ -44,6 +71,10 @@ func NewTaskQueueWorker(service factory.ServiceFactory, q QueueStorage, perst Pe
 					panic("Task Queue Worker: task " + handler.Pattern + " has been registered")
 				}
 
+				if handler.MaxConcurrency <= 0 {
+					handler.MaxConcurrency = 1
+				}
+
 				workerIndex := len(workers)
 				registeredTask[handler.Pattern] = struct {
 					handler     types.WorkerHandler
@@ -59,7 +90,7 @@ func NewTaskQueueWorker(service factory.ServiceFactory, q QueueStorage, perst Pe
 				}
 				tasks = append(tasks, handler.Pattern)
 				workers = append(workers, reflect.SelectCase{Dir: reflect.SelectRecv})
-				semaphore = append(semaphore, make(chan struct{}, 1))
+				semaphore = append(semaphore, make(chan struct{}, handler.MaxConcurrency))
 
 				logger.LogYellow(fmt.Sprintf(`[TASK-QUEUE-WORKER] (task name): %-15s  --> (module): "%s"`, `"`+handler.Pattern+`"`, m.Name()))
 			}
@@ -70,11 +101,12 @@ func NewTaskQueueWorker(service factory.ServiceFactory, q QueueStorage, perst Pe
 		logger.LogYellow("Task Queue Worker: warning, no task provided")
 	}
 
+	// load paused tasks before pending jobs registered to worker
+	syncPausedTasks(workerInstance.ctx)
+
 	go func() {
-		for _, taskName := range tasks {
-			queue.Clear(taskName)
-		}
-		// get current pending jobs
+		// get current pending jobs, job which is still running in another worker instance cannot be claimed
+		// and will be skipped when popped from queue
 		pageNumber := 1
 		filter := Filter{
 			TaskNameList: tasks,
@@ -105,7 +137,18 @@ func (t *taskQueueWorker) Serve() {
 	// serve graphql api for communication to dashboard
 	go serveGraphQLAPI(t)
 
-	// run worker
+	// push due scheduled jobs to queue
+	go t.runScheduler()
+
+	// delete old finished jobs based on retention policies
+	go t.runRetentionSweeper()
+
+	t.runWorker()
+}
+
+// runWorker dispatch jobs of task when worker of task activated until worker shutdown
+func (t *taskQueueWorker) runWorker() {
+	var cases []reflect.SelectCase
 	for {
 		select {
 		case <-shutdown:
@@ -113,7 +156,12 @@ func (t *taskQueueWorker) Serve() {
 		default:
 		}
 
-		chosen, _, ok := reflect.Select(workers)
+		// select cases is copied, worker of task may activated at the same time
+		workerMutex.Lock()
+		cases = append(cases[:0], workers...)
+		workerMutex.Unlock()
+
+		chosen, _, ok := reflect.Select(cases)
 		if !ok {
 			continue
 		}
@@ -123,29 +171,90 @@ func (t *taskQueueWorker) Serve() {
 			continue
 		}
 
-		semaphore[chosen-1] <- struct{}{}
-		if t.isShutdown {
+		t.dispatchJobs(chosen)
+	}
+}
+
+// dispatchJobs execute queued jobs of task in given worker index until queue is empty or task has reached max concurrency,
+// next jobs is dispatched when new job registered to worker, running job finished, or rate limit token available
+func (t *taskQueueWorker) dispatchJobs(workerIndex int) {
+	taskIndex, ok := workerIndexTask[workerIndex]
+	if !ok {
+		return
+	}
+	sem := semaphore[workerIndex-1]
+
+	deactivateWorker(workerIndex)
+	for {
+		// job is kept in queue until task resumed
+		if IsTaskPaused(taskIndex.taskName) || queue.NextJob(taskIndex.taskName) == "" {
+			return
+		}
+
+		select {
+		case sem <- struct{}{}:
+		default:
+			// task has reached max concurrency
+			return
+		}
+		if wait := t.waitRateLimit(taskIndex.taskName); wait > 0 {
+			// job is kept in queue and dispatched again after token available
+			<-sem
+			activateWorker(workerIndex, wait)
+			return
+		}
+		job, ok := t.claimNextJob(taskIndex.taskName)
+		if !ok {
+			<-sem
 			return
 		}
+		if job == nil {
+			<-sem
+			continue
+		}
 
-		t.wg.Add(1)
-		go func(chosen int) {
+		go func() {
 			defer func() {
 				recover()
+				<-sem
+				t.dispatchJobs(workerIndex)
 				t.wg.Done()
-				<-semaphore[chosen-1]
-				refreshWorkerNotif <- struct{}{}
 			}()
 
 			if t.ctx.Err() != nil {
 				logger.LogRed("task_queue_worker > ctx root err: " + t.ctx.Err().Error())
 				return
 			}
-			t.execJob(chosen)
-		}(chosen)
+			t.execJob(job)
+		}()
 	}
 }
 
+// claimNextJob pop job from queue and claim it for this worker instance, nil job is returned if popped job has been
+// stopped or running in another worker instance. Return false when worker shutdown or queue is empty
+func (t *taskQueueWorker) claimNextJob(taskName string) (job *Job, ok bool) {
+	// job is counted under same lock with shutdown state, so that shutdown always wait claimed job
+	workerMutex.Lock()
+	if t.isShutdown {
+		workerMutex.Unlock()
+		return nil, false
+	}
+	t.wg.Add(1)
+	workerMutex.Unlock()
+
+	jobID := queue.PopJob(taskName)
+	if jobID == "" {
+		t.wg.Done()
+		return nil, false
+	}
+	job, err := persistent.ClaimJob(t.ctx, jobID, workerID, time.Now().Add(defaultOption.JobLeaseDuration))
+	if err != nil {
+		t.wg.Done()
+		return nil, true
+	}
+	return job, true
+}
+
 func (t *taskQueueWorker) Shutdown(ctx context.Context) {
 	defer log.Println("\x1b[33;1mStopping Task Queue Worker:\x1b[0m \x1b[32;1mSUCCESS\x1b[0m")
 
@@ -154,7 +263,9 @@ func (t *taskQueueWorker) Shutdown(ctx context.Context) {
 	}
 
 	shutdown <- struct{}{}
+	workerMutex.Lock()
 	t.isShutdown = true
+	workerMutex.Unlock()
 	var runningJob int
 	for _, sem := range semaphore {
 		runningJob += len(sem)
@@ -172,7 +283,11 @@ func (t *taskQueueWorker) Shutdown(ctx context.Context) {
 
 	select {
 	case <-ctx.Done():
-		persistent.UpdateAllStatus(t.ctx, "", []JobStatusEnum{statusRetrying}, statusQueueing)
+		// release lease of running jobs, so that can be claimed immediately by another worker instance
+		runningJobs.Range(func(jobID, _ interface{}) bool {
+			persistent.RenewJobLease(t.ctx, jobID.(string), workerID, time.Now())
+			return true
+		})
 		broadcastAllToSubscribers(t.ctx)
 	case <-done:
 		broadcastAllToSubscribers(t.ctx)
@@ -184,44 +299,48 @@ func (t *taskQueueWorker) Name() string {
 	return string(types.TaskQueue)
 }
 
-func (t *taskQueueWorker) execJob(workerIndex int) {
-	taskIndex, ok := workerIndexTask[workerIndex]
-	if !ok {
-		return
-	}
-
-	taskIndex.activeInterval.Stop()
-	jobID := queue.PopJob(taskIndex.taskName)
-	if jobID == "" {
-		return
-	}
-	job, err := persistent.FindJobByID(t.ctx, jobID)
-	if err != nil {
-		return
-	}
-	if job.Status == string(statusStopped) {
-		nextJobID := queue.NextJob(taskIndex.taskName)
-		if nextJobID != "" {
-			if nextJob, err := persistent.FindJobByID(t.ctx, nextJobID); err == nil {
-				registerJobToWorker(nextJob, workerIndex)
-			}
-		}
-		return
-	}
+// execJob execute job which has been claimed by this worker instance
+func (t *taskQueueWorker) execJob(job *Job) {
+	ctx, cancel := context.WithCancel(t.ctx)
+	running := &runningJob{jobID: job.ID, cancel: cancel}
+	runningJobs.Store(job.ID, running)
+	stopHeartbeat := t.heartbeatJob(job, running)
+	defer func() {
+		stopHeartbeat()
+		runningJobs.Delete(job.ID)
+		cancel()
+	}()
 
-	ctx := t.ctx
 	selectedHandler := registeredTask[job.TaskName].handler
 	if selectedHandler.DisableTrace {
 		ctx = tracer.SkipTraceContext(ctx)
 	}
 
+	history := JobHistory{Retries: job.Retries + 1, StartAt: time.Now()}
 	trace, ctx := tracer.StartTraceWithContext(ctx, "TaskQueueWorker")
 	defer func() {
 		if r := recover(); r != nil {
 			trace.SetError(fmt.Errorf("%v", r))
 		}
 		job.FinishedAt = time.Now()
-		persistent.SaveJob(t.ctx, job)
+		if job.Status != string(statusSuccess) {
+			running.mu.Lock()
+			job.Progress, job.ProgressMessage = running.progress, running.progressMessage
+			running.mu.Unlock()
+		}
+		history.EndAt, history.Status = job.FinishedAt, job.Status
+		if job.Status == string(statusScheduled) {
+			// attempt of job waiting for retry is failed
+			history.Status = string(statusFailure)
+		}
+		job.Histories = append(job.Histories, history)
+		// skip save job when lease has been lost, job has been claimed by another worker instance
+		if running.reason() != errJobLeaseLost {
+			persistent.SaveJob(t.ctx, job)
+			if job.Status != string(statusScheduled) {
+				checkBatchFinished(t.ctx, job.BatchID)
+			}
+		}
 		broadcastAllToSubscribers(t.ctx)
 		logger.LogGreen("task_queue > trace_url: " + tracer.GetTraceURL(ctx))
 		trace.Finish()
@@ -229,10 +348,13 @@ func (t *taskQueueWorker) execJob(workerIndex int) {
 
 	job.Retries++
 	job.Status = string(statusRetrying)
+	job.NextRetryAt = time.Time{}
+	job.Progress, job.ProgressMessage = 0, ""
 	persistent.SaveJob(t.ctx, job)
 	broadcastAllToSubscribers(t.ctx)
 
 	job.TraceID = tracer.GetTraceID(ctx)
+	history.TraceID = job.TraceID
 
 	if env.BaseEnv().DebugMode {
 		log.Printf("\x1b[35;3mTask Queue Worker: executing task '%s'\x1b[0m", job.TaskName)
@@ -242,43 +364,128 @@ func (t *taskQueueWorker) execJob(workerIndex int) {
 	tags["job_id"], tags["task_name"], tags["retries"], tags["max_retry"] = job.ID, job.TaskName, job.Retries, job.MaxRetry
 	tracer.Log(ctx, "job_args", job.Arguments)
 
-	nextJobID := queue.NextJob(taskIndex.taskName)
-	if nextJobID != "" {
-		if nextJob, err := persistent.FindJobByID(t.ctx, nextJobID); err == nil {
-			registerJobToWorker(nextJob, workerIndex)
-		}
+	timeout := selectedHandler.Timeout
+	if jobTimeout, err := time.ParseDuration(job.Timeout); err == nil && jobTimeout > 0 {
+		timeout = jobTimeout
+	}
+	if timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, timeout)
+		defer cancelTimeout()
 	}
 
 	message := []byte(job.Arguments)
 	ctx = context.WithValue(ctx, candishared.ContextKeyTaskQueueRetry, job.Retries)
-	if err := selectedHandler.HandlerFunc(ctx, message); err != nil {
+	ctx = context.WithValue(ctx, contextKeyRunningJob, running)
+	if err := t.runHandler(ctx, running, selectedHandler.HandlerFunc, message); err != nil {
 		job.Error = err.Error()
 		job.Status = string(statusFailure)
+		history.Error = job.Error
 		trace.SetError(err)
 
-		switch e := err.(type) {
-		case *candishared.ErrorRetrier:
-			if job.Retries >= job.MaxRetry {
-				logger.LogRed("TaskQueueWorker: GIVE UP: " + job.TaskName)
-				if selectedHandler.ErrorHandler != nil {
-					selectedHandler.ErrorHandler(ctx, types.TaskQueue, job.TaskName, message, err)
-				}
-				return
+		if err == errJobStopped || err == errJobLeaseLost {
+			job.Status = string(statusStopped)
+			return
+		}
+
+		var isRetry bool
+		var delay time.Duration
+		if e, ok := err.(*candishared.ErrorRetrier); ok {
+			isRetry, delay = true, e.Delay
+		}
+		if err == errJobTimeout {
+			isRetry = true
+		}
+		if selectedHandler.RetryPolicy != nil {
+			isRetry = true
+			if delay <= 0 {
+				delay = selectedHandler.RetryPolicy.NextDelay(job.Retries)
 			}
-			tags["is_retry"] = true
+		}
 
-			job.Status = string(statusQueueing)
-			job.Interval = e.Delay.String()
+		if isRetry && job.Retries < job.MaxRetry {
+			tags["is_retry"] = true
+			if delay <= 0 {
+				delay, _ = time.ParseDuration(defaultInterval)
+			}
 
-			registerJobToWorker(job, workerIndex)
-			queue.PushJob(job)
+			// job is pushed to queue by scheduler when retry time reached, so that job cannot be popped
+			// before retry delay when another job of same task dispatched
+			job.Status = string(statusScheduled)
+			job.Interval = delay.String()
+			job.NextRetryAt = time.Now().Add(delay)
+			job.RunAt = job.NextRetryAt
+			return
+		}
 
-		default:
-			if selectedHandler.ErrorHandler != nil {
-				selectedHandler.ErrorHandler(ctx, types.TaskQueue, job.TaskName, []byte(job.Arguments), err)
-			}
+		if isRetry {
+			logger.LogRed("TaskQueueWorker: GIVE UP: " + job.TaskName)
+		}
+		if selectedHandler.ErrorHandler != nil {
+			selectedHandler.ErrorHandler(ctx, types.TaskQueue, job.TaskName, message, err)
 		}
 	} else {
 		job.Status = string(statusSuccess)
+		running.mu.Lock()
+		job.Result = string(running.result)
+		job.Progress, job.ProgressMessage = maxJobProgress, running.progressMessage
+		running.mu.Unlock()
+		if len(job.NextSteps) > 0 {
+			addNextWorkflowSteps(job)
+		}
+	}
+}
+
+// runHandler execute handler and return immediately when context canceled (timeout or job stopped),
+// even if handler does not respect context cancellation
+func (t *taskQueueWorker) runHandler(ctx context.Context, running *runningJob, handlerFunc types.WorkerHandlerFunc, message []byte) (err error) {
+	result := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				result <- fmt.Errorf("%v", r)
+			}
+		}()
+		result <- handlerFunc(ctx, message)
+	}()
+
+	select {
+	case err = <-result:
+		if err == nil || ctx.Err() == nil {
+			return err
+		}
+	case <-ctx.Done():
+	}
+
+	if reason := running.reason(); reason != nil {
+		return reason
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return errJobTimeout
+	}
+	return ctx.Err()
+}
+
+// stopRunningJob cancel context of job if running in this worker instance, job running in another instance
+// will be canceled when renew lease
+func stopRunningJob(jobID string, reason error) {
+	if running, ok := runningJobs.Load(jobID); ok {
+		running.(*runningJob).stop(reason)
+	}
+}
+
+// waitRateLimit take token from rate limit bucket of given task, return duration to wait if bucket is empty
+func (t *taskQueueWorker) waitRateLimit(taskName string) time.Duration {
+	rateLimit := registeredTask[taskName].handler.RateLimit
+	if rateLimit == nil || rateLimit.Limit <= 0 {
+		return 0
+	}
+
+	wait, err := defaultOption.RateLimiter.Take(t.ctx, taskName, *rateLimit)
+	if err != nil {
+		// job is still dispatched when rate limiter storage is unavailable
+		logger.LogE(fmt.Sprintf("task_queue_worker > rate limit task %s: %v", taskName, err))
+		return 0
 	}
+	return wait
 }
@@ -0,0 +1,361 @@
+package taskqueueworker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunHandler(t *testing.T) {
+	worker := &taskQueueWorker{}
+	hungHandler := func(ctx context.Context, message []byte) error {
+		time.Sleep(time.Second)
+		return nil
+	}
+
+	t.Run("Testcase #1: Handler return error", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		err := worker.runHandler(ctx, &runningJob{cancel: cancel}, func(ctx context.Context, message []byte) error {
+			return errors.New("error")
+		}, nil)
+		assert.EqualError(t, err, "error")
+	})
+
+	t.Run("Testcase #2: Handler timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := worker.runHandler(ctx, &runningJob{cancel: cancel}, hungHandler, nil)
+		assert.Equal(t, errJobTimeout, err)
+	})
+
+	t.Run("Testcase #3: Running job stopped", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		running := &runningJob{cancel: cancel}
+		time.AfterFunc(10*time.Millisecond, func() { running.stop(errJobStopped) })
+		err := worker.runHandler(ctx, running, hungHandler, nil)
+		assert.Equal(t, errJobStopped, err)
+	})
+
+	t.Run("Testcase #4: Handler panic", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		err := worker.runHandler(ctx, &runningJob{cancel: cancel}, func(ctx context.Context, message []byte) error {
+			panic("panic")
+		}, nil)
+		assert.EqualError(t, err, "panic")
+	})
+}
+
+func TestRegisterJobToWorkerInvalidInterval(t *testing.T) {
+	workers = make([]reflect.SelectCase, 2)
+	workerIndexTask = map[int]*struct {
+		taskName       string
+		activeInterval *time.Ticker
+	}{1: {taskName: "task-one"}}
+	defer func() { workers, workerIndexTask = nil, nil }()
+
+	for _, interval := range []string{"0s", "-1s", "invalid"} {
+		assert.NotPanics(t, func() { registerJobToWorker(&Job{Interval: interval}, 1) })
+	}
+	workerIndexTask[1].activeInterval.Stop()
+}
+
+func TestExecJobRetryDelay(t *testing.T) {
+	ctx := context.Background()
+	executed := make(map[string]int)
+	setupTestWorker(t, types.WorkerHandler{Pattern: "task-one", HandlerFunc: func(ctx context.Context, message []byte) error {
+		executed[string(message)]++
+		if string(message) == "retry" {
+			return &candishared.ErrorRetrier{Delay: time.Hour}
+		}
+		return nil
+	}})
+	worker := newTestWorker(t)
+	queueTestJob(&Job{ID: "1", TaskName: "task-one", Arguments: "retry", MaxRetry: 3})
+	queueTestJob(&Job{ID: "2", TaskName: "task-one", Arguments: "success", MaxRetry: 3})
+
+	// retried job is not popped by next dispatch of another job
+	worker.dispatchJobs(1)
+	worker.wg.Wait()
+	assert.Equal(t, map[string]int{"retry": 1, "success": 1}, executed)
+
+	job, _ := persistent.FindJobByID(ctx, "1")
+	assert.Equal(t, string(statusScheduled), job.Status)
+	assert.Equal(t, time.Hour.String(), job.Interval)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), job.RunAt, time.Second)
+	assert.Equal(t, job.RunAt, job.NextRetryAt)
+	worker.enqueueScheduledJobs()
+	assert.Equal(t, "", queue.NextJob("task-one"))
+
+	// job pushed to queue again when retry time reached
+	job.RunAt = time.Now()
+	persistent.SaveJob(ctx, job)
+	worker.enqueueScheduledJobs()
+	job, _ = persistent.FindJobByID(ctx, "1")
+	assert.Equal(t, string(statusQueueing), job.Status)
+	worker.dispatchJobs(1)
+	worker.wg.Wait()
+	assert.Equal(t, 2, executed["retry"])
+}
+
+func TestDispatchJobsMaxConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	var running, maxRunning int
+	setupTestWorker(t, types.WorkerHandler{Pattern: "task-one", MaxConcurrency: 5, HandlerFunc: func(ctx context.Context, message []byte) error {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		time.Sleep(50 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	}})
+	worker := newTestWorker(t)
+	for i := 0; i < 10; i++ {
+		queueTestJob(&Job{ID: fmt.Sprint(i), TaskName: "task-one", MaxRetry: 1})
+	}
+
+	// all queued jobs dispatched in one tick, next job dispatched when running job finished
+	start := time.Now()
+	worker.dispatchJobs(1)
+	worker.wg.Wait()
+	assert.Less(t, int64(time.Since(start)), int64(500*time.Millisecond))
+	assert.Equal(t, 5, maxRunning)
+	assert.Equal(t, 10, persistent.CountAllJob(context.Background(), Filter{Status: []string{string(statusSuccess)}}))
+}
+
+func TestRunWorkerRegisterJobConcurrently(t *testing.T) {
+	ctx := context.Background()
+	setupTestWorker(t,
+		types.WorkerHandler{Pattern: "task-one", MaxConcurrency: 2, HandlerFunc: func(ctx context.Context, message []byte) error { return nil }},
+		types.WorkerHandler{Pattern: "task-two", HandlerFunc: func(ctx context.Context, message []byte) error { return nil }},
+	)
+	worker := newTestWorker(t)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		worker.runWorker()
+	}()
+
+	// job registered to worker from many goroutines (add job, scheduler, resume task) while worker running
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			job := &Job{ID: fmt.Sprint(i), TaskName: tasks[i%2], MaxRetry: 1, Status: string(statusQueueing), Interval: "10ms"}
+			persistent.SaveJob(ctx, job)
+			queue.PushJob(job)
+			registerJobToWorker(job, registeredTask[job.TaskName].workerIndex)
+			refreshWorkerNotif <- struct{}{}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Eventually(t, func() bool {
+		return persistent.CountAllJob(ctx, Filter{Status: []string{string(statusSuccess)}}) == 20
+	}, 5*time.Second, 10*time.Millisecond)
+	shutdown <- struct{}{}
+	refreshWorkerNotif <- struct{}{}
+	<-done
+	worker.wg.Wait()
+}
+
+func TestDispatchJobsClaim(t *testing.T) {
+	ctx := context.Background()
+	var executed []string
+	setupTestWorker(t, types.WorkerHandler{Pattern: "task-one", HandlerFunc: func(ctx context.Context, message []byte) error {
+		executed = append(executed, string(message))
+		return nil
+	}})
+	worker := newTestWorker(t)
+	queueTestJob(&Job{ID: "running", TaskName: "task-one", Arguments: "running", MaxRetry: 1})
+	queueTestJob(&Job{ID: "stopped", TaskName: "task-one", Arguments: "stopped", MaxRetry: 1})
+	queueTestJob(&Job{ID: "expired", TaskName: "task-one", Arguments: "expired", MaxRetry: 1})
+	queueTestJob(&Job{ID: "queueing", TaskName: "task-one", Arguments: "queueing", MaxRetry: 1})
+
+	// job running in another worker instance and stopped job cannot be claimed,
+	// job with expired lease (owner instance has died) is claimed
+	persistent.ClaimJob(ctx, "running", "another-worker", time.Now().Add(time.Minute))
+	persistent.ClaimJob(ctx, "expired", "another-worker", time.Now().Add(-time.Second))
+	stoppedJob, _ := persistent.FindJobByID(ctx, "stopped")
+	stoppedJob.Status = string(statusStopped)
+	persistent.SaveJob(ctx, stoppedJob)
+
+	worker.dispatchJobs(1)
+	worker.wg.Wait()
+	assert.Equal(t, []string{"expired", "queueing"}, executed)
+	assert.Equal(t, "", queue.NextJob("task-one"))
+
+	job, _ := persistent.FindJobByID(ctx, "running")
+	assert.Equal(t, string(statusRetrying), job.Status)
+	assert.Equal(t, "another-worker", job.WorkerID)
+	job, _ = persistent.FindJobByID(ctx, "expired")
+	assert.Equal(t, string(statusSuccess), job.Status)
+	assert.Equal(t, workerID, job.WorkerID)
+	job, _ = persistent.FindJobByID(ctx, "stopped")
+	assert.Equal(t, string(statusStopped), job.Status)
+}
+
+func TestShutdownReleaseJobLease(t *testing.T) {
+	ctx := context.Background()
+	started, finish := make(chan struct{}), make(chan struct{})
+	setupTestWorker(t, types.WorkerHandler{Pattern: "task-one", HandlerFunc: func(ctx context.Context, message []byte) error {
+		close(started)
+		<-finish
+		return nil
+	}})
+	worker := newTestWorker(t)
+	queueTestJob(&Job{ID: "1", TaskName: "task-one", MaxRetry: 1})
+	worker.dispatchJobs(1)
+	<-started
+
+	job, _ := persistent.FindJobByID(ctx, "1")
+	assert.Equal(t, string(statusRetrying), job.Status)
+	assert.WithinDuration(t, time.Now().Add(defaultOption.JobLeaseDuration), job.LeaseUntil, time.Second)
+
+	// running job lease released when shutdown timeout, so that job can be claimed by another worker instance
+	shutdownCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	worker.Shutdown(shutdownCtx)
+	job, _ = persistent.FindJobByID(ctx, "1")
+	assert.False(t, job.LeaseUntil.After(time.Now()))
+	_, err := persistent.ClaimJob(ctx, "1", "another-worker", time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+
+	// job is not dispatched after shutdown
+	queueTestJob(&Job{ID: "2", TaskName: "task-one", MaxRetry: 1})
+	worker.dispatchJobs(1)
+	close(finish)
+	worker.wg.Wait()
+	job, _ = persistent.FindJobByID(ctx, "2")
+	assert.Equal(t, string(statusQueueing), job.Status)
+}
+
+func TestExecJobTimeout(t *testing.T) {
+	ctx := context.Background()
+	started := make(chan struct{}, 1)
+	hungHandler := func(ctx context.Context, message []byte) error {
+		started <- struct{}{}
+		time.Sleep(time.Second)
+		return nil
+	}
+	setupTestWorker(t,
+		types.WorkerHandler{Pattern: "task-one", Timeout: 10 * time.Millisecond, HandlerFunc: hungHandler},
+		types.WorkerHandler{Pattern: "task-two", HandlerFunc: hungHandler},
+	)
+	worker := newTestWorker(t)
+
+	// job marked as failure when timeout and max retry reached, timeout of job override timeout of task
+	queueTestJob(&Job{ID: "1", TaskName: "task-one", MaxRetry: 1})
+	queueTestJob(&Job{ID: "2", TaskName: "task-two", MaxRetry: 1, Timeout: "10ms"})
+	worker.dispatchJobs(1)
+	worker.dispatchJobs(2)
+	worker.wg.Wait()
+	for _, id := range []string{"1", "2"} {
+		job, _ := persistent.FindJobByID(ctx, id)
+		assert.Equal(t, string(statusFailure), job.Status)
+		assert.Equal(t, errJobTimeout.Error(), job.Error)
+	}
+	<-started
+	<-started
+
+	// timeout job is retried
+	queueTestJob(&Job{ID: "3", TaskName: "task-one", MaxRetry: 2})
+	worker.dispatchJobs(1)
+	worker.wg.Wait()
+	job, _ := persistent.FindJobByID(ctx, "3")
+	assert.Equal(t, string(statusScheduled), job.Status)
+	<-started
+
+	// running job stopped
+	queueTestJob(&Job{ID: "4", TaskName: "task-two", MaxRetry: 2})
+	worker.dispatchJobs(2)
+	<-started
+	_, err := StopJobs(ctx, Filter{TaskName: "task-two"})
+	assert.NoError(t, err)
+	worker.wg.Wait()
+	job, _ = persistent.FindJobByID(ctx, "4")
+	assert.Equal(t, string(statusStopped), job.Status)
+	assert.Equal(t, errJobStopped.Error(), job.Error)
+}
+
+func TestExecJobHistory(t *testing.T) {
+	ctx := context.Background()
+	var attempt int
+	setupTestWorker(t, types.WorkerHandler{Pattern: "task-one", HandlerFunc: func(ctx context.Context, message []byte) error {
+		attempt++
+		if attempt == 1 {
+			return &candishared.ErrorRetrier{Delay: time.Hour, Message: "failed"}
+		}
+		return nil
+	}})
+	worker := newTestWorker(t)
+	queueTestJob(&Job{ID: "1", TaskName: "task-one", MaxRetry: 3})
+
+	worker.dispatchJobs(1)
+	worker.wg.Wait()
+	job, _ := persistent.FindJobByID(ctx, "1")
+	assert.Len(t, job.Histories, 1)
+	assert.Equal(t, 1, job.Histories[0].Retries)
+	assert.Equal(t, string(statusFailure), job.Histories[0].Status)
+	assert.Equal(t, "failed", job.Histories[0].Error)
+	assert.False(t, job.Histories[0].EndAt.Before(job.Histories[0].StartAt))
+
+	// history of each attempt is appended
+	job.RunAt = time.Now()
+	persistent.SaveJob(ctx, job)
+	worker.enqueueScheduledJobs()
+	worker.dispatchJobs(1)
+	worker.wg.Wait()
+	job, _ = persistent.FindJobByID(ctx, "1")
+	assert.Equal(t, string(statusSuccess), job.Status)
+	assert.Len(t, job.Histories, 2)
+	assert.Equal(t, 2, job.Histories[1].Retries)
+	assert.Equal(t, string(statusSuccess), job.Histories[1].Status)
+	assert.Equal(t, "", job.Histories[1].Error)
+}
+
+func TestDispatchJobsRateLimit(t *testing.T) {
+	ctx := context.Background()
+	setupTestWorker(t, types.WorkerHandler{Pattern: "task-one", MaxConcurrency: 5,
+		RateLimit:   &candishared.RateLimit{Limit: 2, Interval: time.Second},
+		HandlerFunc: func(ctx context.Context, message []byte) error { return nil }})
+	worker := newTestWorker(t)
+	for i := 0; i < 4; i++ {
+		queueTestJob(&Job{ID: fmt.Sprint(i), TaskName: "task-one", MaxRetry: 1})
+	}
+	success := Filter{TaskName: "task-one", Status: []string{string(statusSuccess)}}
+
+	// only jobs within rate limit dispatched, remaining jobs kept in queue
+	worker.dispatchJobs(1)
+	worker.wg.Wait()
+	assert.Equal(t, 2, persistent.CountAllJob(ctx, success))
+	assert.Equal(t, 2, persistent.CountAllJob(ctx, Filter{TaskName: "task-one", Status: []string{string(statusQueueing)}}))
+	assert.NotEqual(t, "", queue.NextJob("task-one"))
+
+	// worker activated again after token available
+	start := time.Now()
+	select {
+	case <-workerIndexTask[1].activeInterval.C:
+	case <-time.After(time.Second):
+		t.Fatal("worker not activated after rate limit wait")
+	}
+	assert.InDelta(t, float64(500*time.Millisecond), float64(time.Since(start)), float64(100*time.Millisecond))
+	worker.dispatchJobs(1)
+	worker.wg.Wait()
+	assert.Equal(t, 3, persistent.CountAllJob(ctx, success))
+}
@@ -0,0 +1,70 @@
+package taskqueueworker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/golangid/candi/codebase/factory/types"
+)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// ValidateArgsJSONSchema job arguments validator using json schema with given schema ID,
+// jsonSchema can be validator.JSONSchemaValidator or service validator (interfaces.Validator).
+// Use with types.WorkerHandlerOptionArgsValidator when register task handler
+func ValidateArgsJSONSchema(jsonSchema interface {
+	ValidateDocument(reference string, document interface{}) error
+}, schemaID string) func(args []byte) error {
+	return func(args []byte) error {
+		return jsonSchema.ValidateDocument(schemaID, args)
+	}
+}
+
+// ValidateArgsType job arguments validator, arguments must be valid json of type of given value (example: MyArgs{}).
+// Use with types.WorkerHandlerOptionArgsValidator when register task handler
+func ValidateArgsType(argsType interface{}) func(args []byte) error {
+	typ := reflect.TypeOf(argsType)
+	if typ == nil {
+		panic("Task Queue Worker: arguments type cannot nil")
+	}
+	return func(args []byte) error {
+		return json.Unmarshal(args, reflect.New(typ).Interface())
+	}
+}
+
+// AddTypedJob public function for add new job with typed arguments, arguments is encoded as json
+func AddTypedJob(taskName string, maxRetry int, args interface{}, opts ...AddJobOptionFunc) (jobID string, err error) {
+	message, err := json.Marshal(args)
+	if err != nil {
+		return "", fmt.Errorf("invalid arguments for task '%s': %v", taskName, err)
+	}
+	return AddJobWithOption(taskName, maxRetry, message, opts...)
+}
+
+// TypedHandler wrap handler with typed arguments, job arguments is decoded from json before handler called.
+// handlerFunc must have signature func(ctx context.Context, args T) error, panic if not
+func TypedHandler(handlerFunc interface{}) types.WorkerHandlerFunc {
+	fn, fnType := reflect.ValueOf(handlerFunc), reflect.TypeOf(handlerFunc)
+	if fnType == nil || fnType.Kind() != reflect.Func || fnType.NumIn() != 2 || fnType.NumOut() != 1 ||
+		fnType.In(0) != contextType || fnType.Out(0) != errorType {
+		panic(fmt.Sprintf("Task Queue Worker: invalid typed handler %T, must func(context.Context, T) error", handlerFunc))
+	}
+	argsType := fnType.In(1)
+
+	return func(ctx context.Context, message []byte) error {
+		args := reflect.New(argsType)
+		if err := json.Unmarshal(message, args.Interface()); err != nil {
+			return fmt.Errorf("invalid arguments: %v", err)
+		}
+		out := fn.Call([]reflect.Value{reflect.ValueOf(&ctx).Elem(), args.Elem()})
+		if err, _ := out[0].Interface().(error); err != nil {
+			return err
+		}
+		return nil
+	}
+}
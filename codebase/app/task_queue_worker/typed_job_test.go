@@ -0,0 +1,64 @@
+package taskqueueworker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type typedJobArgs struct {
+	UserID string `json:"user_id"`
+	Amount int    `json:"amount"`
+}
+
+type jsonSchemaFunc func(reference string, document interface{}) error
+
+func (f jsonSchemaFunc) ValidateDocument(reference string, document interface{}) error {
+	return f(reference, document)
+}
+
+func TestTypedJob(t *testing.T) {
+	ctx := context.Background()
+	setupTestWorker(t,
+		types.WorkerHandler{Pattern: "task-one", ArgsValidator: ValidateArgsType(typedJobArgs{})},
+		types.WorkerHandler{Pattern: "task-two", ArgsValidator: ValidateArgsJSONSchema(jsonSchemaFunc(func(reference string, document interface{}) error {
+			assert.Equal(t, "task-two", reference)
+			return errors.New("amount is required")
+		}), "task-two")},
+	)
+
+	// scheduled job is not pushed to worker
+	runAt := AddJobOptionRunAt(time.Now().Add(time.Hour))
+
+	_, err := AddJobWithOption("task-one", 1, []byte(`{"amount":"1"}`), runAt)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid arguments for task 'task-one'")
+	_, err = AddJobWithOption("task-two", 1, []byte(`{}`))
+	assert.EqualError(t, err, "invalid arguments for task 'task-two': amount is required")
+
+	jobID, err := AddTypedJob("task-one", 1, typedJobArgs{UserID: "1", Amount: 10}, runAt)
+	assert.NoError(t, err)
+	job, err := persistent.FindJobByID(ctx, jobID)
+	assert.NoError(t, err)
+
+	var received typedJobArgs
+	handler := TypedHandler(func(ctx context.Context, args typedJobArgs) error {
+		received = args
+		return nil
+	})
+	assert.NoError(t, handler(ctx, []byte(job.Arguments)))
+	assert.Equal(t, typedJobArgs{UserID: "1", Amount: 10}, received)
+	assert.Error(t, handler(ctx, []byte("invalid")))
+
+	handler = TypedHandler(func(ctx context.Context, args *typedJobArgs) error {
+		return errors.New(args.UserID)
+	})
+	assert.EqualError(t, handler(ctx, []byte(job.Arguments)), "1")
+
+	assert.Panics(t, func() { TypedHandler(func(args typedJobArgs) error { return nil }) })
+	assert.Panics(t, func() { TypedHandler(nil) })
+}
@@ -2,13 +2,16 @@ package taskqueueworker
 
 import (
 	"errors"
+	"fmt"
 	"net/url"
+	"os"
 	"reflect"
 	"sync"
 	"time"
 
 	"github.com/golangid/candi/codebase/factory/types"
 	"github.com/golangid/candi/config/env"
+	"github.com/google/uuid"
 )
 
 type (
@@ -39,10 +42,13 @@ type (
 	}
 	// TaskResolver resolver
 	TaskResolver struct {
-		Name      string
-		TotalJobs int
-		Detail    struct {
-			Failure, Retrying, Success, Queueing, Stopped int
+		Name           string
+		TotalJobs      int
+		MaxConcurrency int
+		RunningJobs    int
+		IsPaused       bool
+		Detail         struct {
+			Failure, Retrying, Success, Queueing, Stopped, Scheduled int
 		}
 	}
 	// TaskListResolver resolver
@@ -59,7 +65,7 @@ type (
 		TotalPages     int
 		IsCloseSession bool
 		Detail         struct {
-			Failure, Retrying, Success, Queueing, Stopped int
+			Failure, Retrying, Success, Queueing, Stopped, Scheduled int
 		}
 	}
 
@@ -74,9 +80,17 @@ type (
 		Page, Limit  int
 		TaskName     string
 		TaskNameList []string
+		BatchID      string
 		Search       *string
 		Status       []string
 		ShowAll      bool
+		RunAtBefore  *time.Time
+		// CreatedAfter & CreatedBefore filter job created in given time range
+		CreatedAfter, CreatedBefore *time.Time
+		// LeaseExpiredBefore filter job with lease time before given time
+		LeaseExpiredBefore *time.Time
+		// FinishedBefore filter job finished before given time (job never executed is filtered by created time)
+		FinishedBefore *time.Time
 	}
 
 	clientJobTaskSubscriber struct {
@@ -89,11 +103,12 @@ type (
 )
 
 const (
-	statusRetrying JobStatusEnum = "RETRYING"
-	statusFailure  JobStatusEnum = "FAILURE"
-	statusSuccess  JobStatusEnum = "SUCCESS"
-	statusQueueing JobStatusEnum = "QUEUEING"
-	statusStopped  JobStatusEnum = "STOPPED"
+	statusRetrying  JobStatusEnum = "RETRYING"
+	statusFailure   JobStatusEnum = "FAILURE"
+	statusSuccess   JobStatusEnum = "SUCCESS"
+	statusQueueing  JobStatusEnum = "QUEUEING"
+	statusStopped   JobStatusEnum = "STOPPED"
+	statusScheduled JobStatusEnum = "SCHEDULED"
 )
 
 var (
@@ -107,6 +122,8 @@ var (
 		taskName       string
 		activeInterval *time.Ticker
 	}
+	// workerMutex guard select cases of workers, active interval of each task and shutdown state of worker
+	workerMutex sync.Mutex
 
 	queue                                             QueueStorage
 	persistent                                        Persistent
@@ -119,6 +136,19 @@ var (
 	clientJobTaskSubscribers map[string]clientJobTaskSubscriber
 
 	errClientLimitExceeded = errors.New("client limit exceeded, please try again later")
+	errJobNotClaimed       = errors.New("job is not available or has been claimed by another worker instance")
+	errJobLeaseLost        = errors.New("job lease has been lost")
+	errUniqueKeyTaken      = errors.New("unique key of job has been taken by another job")
+	errJobStopped          = errors.New("job has been stopped")
+	errJobTimeout          = errors.New("job execution timeout")
+
+	contextKeyRunningJob = struct{ name string }{name: "taskQueueRunningJob"}
+
+	// runningJobs all jobs executed in this worker instance, key is job ID and value is *runningJob
+	runningJobs sync.Map
+
+	// workerID unique identifier of this worker instance, used as owner of running job
+	workerID string
 
 	defaultOption option
 )
@@ -128,6 +158,9 @@ func makeAllGlobalVars(q QueueStorage, perst Persistent, opts ...OptionFunc) {
 	queue = q
 	persistent = perst
 
+	hostname, _ := os.Hostname()
+	workerID = hostname + "-" + uuid.New().String()
+
 	if env.BaseEnv().JaegerTracingDashboard != "" {
 		defaultOption.JaegerTracingDashboard = env.BaseEnv().JaegerTracingDashboard
 	} else if urlTracerAgent, _ := url.Parse("//" + env.BaseEnv().JaegerTracingHost); urlTracerAgent != nil {
@@ -135,6 +168,10 @@ func makeAllGlobalVars(q QueueStorage, perst Persistent, opts ...OptionFunc) {
 	}
 	defaultOption.MaxClientSubscriber = env.BaseEnv().TaskQueueDashboardMaxClientSubscribers
 	defaultOption.AutoRemoveClientInterval = 30 * time.Minute
+	defaultOption.CheckScheduledJobInterval = time.Second
+	defaultOption.JobLeaseDuration = time.Minute
+	defaultOption.RetentionSweepInterval = time.Hour
+	defaultOption.RateLimiter = NewInMemRateLimiter()
 	defaultOption.DashboardBanner = `
     _________    _   ______  ____
    / ____/   |  / | / / __ \/  _/
@@ -145,6 +182,9 @@ func makeAllGlobalVars(q QueueStorage, perst Persistent, opts ...OptionFunc) {
 	for _, opt := range opts {
 		opt(&defaultOption)
 	}
+	if defaultOption.JobLeaseDuration < minJobLeaseDuration {
+		panic(fmt.Sprintf("Task Queue Worker: job lease duration must be at least %s", minJobLeaseDuration))
+	}
 
 	refreshWorkerNotif, shutdown, closeAllSubscribers = make(chan struct{}), make(chan struct{}, 1), make(chan struct{})
 	clientTaskSubscribers = make(map[string]chan TaskListResolver, defaultOption.MaxClientSubscriber)
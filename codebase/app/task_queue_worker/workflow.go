@@ -0,0 +1,101 @@
+package taskqueueworker
+
+import (
+	"context"
+	"fmt"
+)
+
+type (
+	// WorkflowStep model, step of workflow, next steps will be added as new jobs when this step succeeded
+	WorkflowStep struct {
+		TaskName string `bson:"task_name" json:"task_name"`
+		MaxRetry int    `bson:"max_retry" json:"max_retry"`
+		// Args job arguments, if empty will be filled with result of parent step (see SetJobResult)
+		Args      []byte         `bson:"args" json:"args"`
+		NextSteps []WorkflowStep `bson:"next_steps" json:"next_steps"`
+	}
+
+	// WorkflowResolver resolver, tree of jobs in workflow
+	WorkflowResolver struct {
+		Job       Job
+		Children  []WorkflowResolver
+		NextSteps []string
+	}
+)
+
+// AddWorkflow public function for add new workflow, root step will be added as new job and all next steps
+// will be chained until all steps succeeded. Workflow is stopped when a step give up (failure after max retry)
+func AddWorkflow(root WorkflowStep, opts ...AddJobOptionFunc) (jobID string, err error) {
+	if err := validateWorkflowStep(root); err != nil {
+		return "", err
+	}
+
+	opts = append(opts, func(o *addJobOption) {
+		o.nextSteps = root.NextSteps
+	})
+	return AddJobWithOption(root.TaskName, root.MaxRetry, root.Args, opts...)
+}
+
+// SetJobResult set result of running job from handler context, result will be used as arguments of next workflow steps
+func SetJobResult(ctx context.Context, result []byte) {
+	if running, ok := ctx.Value(contextKeyRunningJob).(*runningJob); ok {
+		running.mu.Lock()
+		running.result = result
+		running.mu.Unlock()
+	}
+}
+
+func validateWorkflowStep(step WorkflowStep) error {
+	if _, err := validateJob(step.TaskName, step.MaxRetry, addJobOption{}); err != nil {
+		return err
+	}
+	for _, next := range step.NextSteps {
+		if err := validateWorkflowStep(next); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addNextWorkflowSteps add next steps of succeeded job as new jobs
+func addNextWorkflowSteps(job *Job) {
+	workflowID := job.WorkflowID
+	if workflowID == "" {
+		workflowID = job.ID
+	}
+
+	for _, step := range job.NextSteps {
+		args := step.Args
+		if len(args) == 0 {
+			args = []byte(job.Result)
+		}
+
+		step := step
+		childID, err := AddJobWithOption(step.TaskName, step.MaxRetry, args, func(o *addJobOption) {
+			o.workflowID, o.parentID, o.nextSteps = workflowID, job.ID, step.NextSteps
+		})
+		if err != nil {
+			job.Error = fmt.Sprintf("failed add next step '%s': %v", step.TaskName, err)
+			continue
+		}
+		job.ChildIDs = append(job.ChildIDs, childID)
+	}
+	job.NextSteps = nil
+}
+
+// getWorkflowTree get all jobs in workflow from given root job
+func getWorkflowTree(ctx context.Context, job *Job) (res WorkflowResolver) {
+	job.updateValue()
+	res.Job = *job
+	for _, step := range job.NextSteps {
+		res.NextSteps = append(res.NextSteps, step.TaskName)
+	}
+	for _, childID := range job.ChildIDs {
+		child, err := persistent.FindJobByID(ctx, childID)
+		if err != nil {
+			continue
+		}
+		res.Children = append(res.Children, getWorkflowTree(ctx, child))
+	}
+	return
+}
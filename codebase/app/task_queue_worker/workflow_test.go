@@ -0,0 +1,104 @@
+package taskqueueworker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddWorkflow(t *testing.T) {
+	_, err := AddWorkflow(WorkflowStep{TaskName: "unregistered-task", MaxRetry: 1})
+	assert.Error(t, err)
+}
+
+func TestExecWorkflowSteps(t *testing.T) {
+	ctx := context.Background()
+	var stepArgs []string
+	setupTestWorker(t,
+		types.WorkerHandler{Pattern: "task-one", HandlerFunc: func(ctx context.Context, message []byte) error {
+			SetJobResult(ctx, []byte("result"))
+			return nil
+		}},
+		types.WorkerHandler{Pattern: "task-two", HandlerFunc: func(ctx context.Context, message []byte) error {
+			stepArgs = append(stepArgs, string(message))
+			return nil
+		}},
+		types.WorkerHandler{Pattern: "task-three", HandlerFunc: func(ctx context.Context, message []byte) error {
+			return errors.New("failed")
+		}},
+	)
+	worker := newTestWorker(t)
+	// next steps is added asynchronously, jobs of task is dispatched until given total jobs finished
+	execSteps := func(taskName string, totalJobs int) (jobs []Job) {
+		filter := Filter{TaskName: taskName, Status: []string{string(statusSuccess), string(statusFailure)}, ShowAll: true}
+		assert.Eventually(t, func() bool {
+			worker.dispatchJobs(registeredTask[taskName].workerIndex)
+			worker.wg.Wait()
+			jobs = persistent.FindAllJob(ctx, filter)
+			return len(jobs) == totalJobs
+		}, time.Second, time.Millisecond)
+		return jobs
+	}
+
+	_, err := AddWorkflow(WorkflowStep{TaskName: "task-one", MaxRetry: 1, NextSteps: []WorkflowStep{
+		{TaskName: "task-two", MaxRetry: 1, NextSteps: []WorkflowStep{{TaskName: "task-three", MaxRetry: 1, NextSteps: []WorkflowStep{
+			{TaskName: "task-two", MaxRetry: 1},
+		}}}},
+		{TaskName: "task-two", MaxRetry: 1, Args: []byte("args")},
+	}})
+	assert.NoError(t, err)
+
+	// next steps added as child jobs when step succeeded, step without args use result of parent step
+	root := execSteps("task-one", 1)[0]
+	assert.Equal(t, string(statusSuccess), root.Status)
+	assert.Equal(t, "result", root.Result)
+	assert.Len(t, root.ChildIDs, 2)
+	assert.Empty(t, root.NextSteps)
+
+	child, _ := persistent.FindJobByID(ctx, root.ChildIDs[0])
+	assert.Equal(t, root.ID, child.WorkflowID)
+	assert.Equal(t, root.ID, child.ParentID)
+	assert.Equal(t, "task-three", child.NextSteps[0].TaskName)
+	execSteps("task-two", 2)
+	assert.ElementsMatch(t, []string{"result", "args"}, stepArgs)
+
+	// workflow stopped when step give up
+	step := execSteps("task-three", 1)[0]
+	assert.Equal(t, string(statusFailure), step.Status)
+	assert.Equal(t, root.ID, step.WorkflowID)
+	assert.Empty(t, step.ChildIDs)
+	assert.Equal(t, "", queue.NextJob("task-two"))
+
+	tree := getWorkflowTree(ctx, &root)
+	assert.Len(t, tree.Children, 2)
+	assert.Len(t, tree.Children[0].Children, 1)
+
+	// wait all added jobs (workflow root and 3 steps) notified to worker before global state restored
+	for i := 0; i < 4; i++ {
+		select {
+		case <-refreshWorkerNotif:
+		case <-time.After(time.Second):
+			t.Fatal("added job not notified to worker")
+		}
+	}
+}
+
+func TestGetWorkflowTree(t *testing.T) {
+	ctx := context.Background()
+	setupTestWorker(t)
+
+	persistent.SaveJob(ctx, &Job{ID: "root", TaskName: "task-one", ChildIDs: []string{"child-1", "child-2"}})
+	persistent.SaveJob(ctx, &Job{ID: "child-1", TaskName: "task-two", WorkflowID: "root", ParentID: "root"})
+	persistent.SaveJob(ctx, &Job{ID: "child-2", TaskName: "task-two", WorkflowID: "root", ParentID: "root",
+		NextSteps: []WorkflowStep{{TaskName: "task-three", MaxRetry: 1}}})
+
+	root, _ := persistent.FindJobByID(ctx, "root")
+	tree := getWorkflowTree(ctx, root)
+	assert.Equal(t, "root", tree.Job.ID)
+	assert.Len(t, tree.Children, 2)
+	assert.Equal(t, []string{"task-three"}, tree.Children[1].NextSteps)
+}
@@ -12,6 +12,7 @@ import (
 	taskqueueworker "github.com/golangid/candi/codebase/app/task_queue_worker"
 	"github.com/golangid/candi/codebase/factory"
 	"github.com/golangid/candi/config/env"
+	"github.com/golangid/candi/middleware"
 )
 
 /*
@@ -47,18 +48,82 @@ func NewAppFromEnvironmentConfig(service factory.ServiceFactory) (apps []factory
 		apps = append(apps, kafkaworker.NewWorker(service))
 	}
 	if env.BaseEnv().UseCronScheduler {
-		apps = append(apps, cronworker.NewWorker(service))
+		var opts []cronworker.OptionFunc
+		if env.BaseEnv().CronWorkerDashboardPort > 0 {
+			opts = append(opts, cronworker.SetDashboardPort(env.BaseEnv().CronWorkerDashboardPort))
+		}
+		if env.BaseEnv().CronWorkerDashboardAuth != "" {
+			if service.GetDependency().GetMiddleware() == nil {
+				panic("Cron worker: dashboard auth require middleware")
+			}
+			opts = append(opts, cronworker.SetDashboardAuth(middleware.DashboardAuth{
+				Middleware:             service.GetDependency().GetMiddleware(),
+				AuthType:               env.BaseEnv().CronWorkerDashboardAuth,
+				ReadPermissionCode:     env.BaseEnv().CronWorkerDashboardReadPermission,
+				OperatorPermissionCode: env.BaseEnv().CronWorkerDashboardOperatorPermission,
+			}))
+		}
+		switch deps := service.GetDependency(); env.BaseEnv().CronWorkerHistoryStore {
+		case "mongo":
+			if deps.GetMongoDatabase() == nil {
+				panic("Cron worker: history store require mongodb")
+			}
+			opts = append(opts, cronworker.SetHistoryStore(cronworker.NewMongoHistoryStore(deps.GetMongoDatabase().WriteDB())))
+		case "sql":
+			if deps.GetSQLDatabase() == nil {
+				panic("Cron worker: history store require sql database")
+			}
+			opts = append(opts, cronworker.SetHistoryStore(cronworker.NewSQLHistoryStore(deps.GetSQLDatabase().WriteDB())))
+		case "redis":
+			if deps.GetRedisPool() == nil {
+				panic("Cron worker: history store require redis")
+			}
+			opts = append(opts, cronworker.SetHistoryStore(cronworker.NewRedisHistoryStore(deps.GetRedisPool().WritePool())))
+		}
+		apps = append(apps, cronworker.NewWorker(service, opts...))
 	}
 	if env.BaseEnv().UseTaskQueueWorker {
-		if service.GetDependency().GetRedisPool() == nil {
-			panic("Task queue worker require redis for queue")
+		var queue taskqueueworker.QueueStorage
+		var persistent taskqueueworker.Persistent
+		if service.GetDependency().GetRedisPool() != nil {
+			queue = taskqueueworker.NewRedisQueue(service.GetDependency().GetRedisPool().WritePool())
+		}
+		if service.GetDependency().GetMongoDatabase() != nil {
+			persistent = taskqueueworker.NewMongoPersistent(service.GetDependency().GetMongoDatabase().WriteDB())
+		} else if service.GetDependency().GetSQLDatabase() != nil {
+			persistent = taskqueueworker.NewSQLPersistent(service.GetDependency().GetSQLDatabase().WriteDB())
+		}
+
+		// fallback to embedded file storage if redis or database is not configured
+		if persistent == nil {
+			fileStorage := taskqueueworker.NewFileStorage(env.BaseEnv().TaskQueueFileStoragePath)
+			persistent = fileStorage
+			if queue == nil {
+				queue = fileStorage
+			}
+		}
+		if queue == nil {
+			queue = taskqueueworker.NewInMemQueue()
+		}
+		var opts []taskqueueworker.OptionFunc
+		if service.GetDependency().GetRedisPool() != nil {
+			// share rate limit of task across worker instances
+			opts = append(opts, taskqueueworker.SetRateLimiter(taskqueueworker.NewRedisRateLimiter(service.GetDependency().GetRedisPool().WritePool())))
 		}
-		if service.GetDependency().GetMongoDatabase() == nil {
-			panic("Task queue worker require mongo for dashboard management")
+		if env.BaseEnv().TaskQueueDashboardAuth != "" {
+			if service.GetDependency().GetMiddleware() == nil {
+				panic("Task queue worker: dashboard auth require middleware")
+			}
+			opts = append(opts, taskqueueworker.SetDashboardAuth(taskqueueworker.DashboardAuth{
+				DashboardAuth: middleware.DashboardAuth{
+					Middleware:             service.GetDependency().GetMiddleware(),
+					AuthType:               env.BaseEnv().TaskQueueDashboardAuth,
+					ReadPermissionCode:     env.BaseEnv().TaskQueueDashboardReadPermission,
+					OperatorPermissionCode: env.BaseEnv().TaskQueueDashboardOperatorPermission,
+				},
+			}))
 		}
-		queue := taskqueueworker.NewRedisQueue(service.GetDependency().GetRedisPool().WritePool())
-		persistent := taskqueueworker.NewMongoPersistent(service.GetDependency().GetMongoDatabase().WriteDB())
-		apps = append(apps, taskqueueworker.NewTaskQueueWorker(service, queue, persistent))
+		apps = append(apps, taskqueueworker.NewTaskQueueWorker(service, queue, persistent, opts...))
 	}
 	if env.BaseEnv().UseRedisSubscriber {
 		apps = append(apps, redisworker.NewWorker(service))
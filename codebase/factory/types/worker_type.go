@@ -1,6 +1,11 @@
 package types
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/golangid/candi/candishared"
+)
 
 type (
 	// WorkerHandlerFunc types
@@ -16,6 +21,24 @@ type (
 		ErrorHandler WorkerErrorHandler
 		DisableTrace bool
 		AutoACK      bool
+
+		// RetryPolicy for task queue worker, if set job will be retried when handler return any error.
+		// Job waiting for retry is scheduled and pushed to queue again by scheduler after retry delay
+		RetryPolicy *candishared.RetryPolicy
+		// MaxConcurrency for task queue worker, max number of jobs executed in parallel (default is 1)
+		MaxConcurrency int
+		// Timeout for task queue worker and cron worker, max duration of job execution before context canceled and job marked as failure
+		Timeout time.Duration
+		// RateLimit for task queue worker, limit dispatch rate of job (token bucket)
+		RateLimit *candishared.RateLimit
+		// ArgsValidator for task queue worker, validate arguments when job added, job is rejected if return error
+		ArgsValidator func(args []byte) error
+		// Timezone for cron worker, IANA timezone name (example: Asia/Jakarta) of job schedule
+		Timezone string
+		// MisfirePolicy for cron worker, execution of missed activation when service is down (skip, run_once, or run_all)
+		MisfirePolicy string
+		// OverlapPolicy for cron worker, new run when previous run of job is still running (allow, skip, or queue)
+		OverlapPolicy string
 	}
 
 	// WorkerHandlerOptionFunc types
@@ -59,3 +82,61 @@ func WorkerHandlerOptionAddErrorHandler(errHandler WorkerErrorHandler) WorkerHan
 		wh.ErrorHandler = errHandler
 	}
 }
+
+// WorkerHandlerOptionRetryPolicy set retry policy (only for task queue worker)
+func WorkerHandlerOptionRetryPolicy(policy candishared.RetryPolicy) WorkerHandlerOptionFunc {
+	return func(wh *WorkerHandler) {
+		wh.RetryPolicy = &policy
+	}
+}
+
+// WorkerHandlerOptionMaxConcurrency set max concurrency (only for task queue worker)
+func WorkerHandlerOptionMaxConcurrency(n int) WorkerHandlerOptionFunc {
+	return func(wh *WorkerHandler) {
+		wh.MaxConcurrency = n
+	}
+}
+
+// WorkerHandlerOptionTimeout set job execution timeout (only for task queue worker and cron worker)
+func WorkerHandlerOptionTimeout(timeout time.Duration) WorkerHandlerOptionFunc {
+	return func(wh *WorkerHandler) {
+		wh.Timeout = timeout
+	}
+}
+
+// WorkerHandlerOptionRateLimit set job dispatch rate limit (only for task queue worker)
+func WorkerHandlerOptionRateLimit(rateLimit candishared.RateLimit) WorkerHandlerOptionFunc {
+	return func(wh *WorkerHandler) {
+		wh.RateLimit = &rateLimit
+	}
+}
+
+// WorkerHandlerOptionArgsValidator set job arguments validator (only for task queue worker)
+func WorkerHandlerOptionArgsValidator(validate func(args []byte) error) WorkerHandlerOptionFunc {
+	return func(wh *WorkerHandler) {
+		wh.ArgsValidator = validate
+	}
+}
+
+// WorkerHandlerOptionTimezone set timezone of job schedule with IANA timezone name, example: Asia/Jakarta (only for cron worker)
+func WorkerHandlerOptionTimezone(timezone string) WorkerHandlerOptionFunc {
+	return func(wh *WorkerHandler) {
+		wh.Timezone = timezone
+	}
+}
+
+// WorkerHandlerOptionMisfirePolicy set policy for missed activation when service is down (only for cron worker),
+// policy must one of cronworker.MisfirePolicySkip (default), cronworker.MisfirePolicyRunOnce, or cronworker.MisfirePolicyRunAll
+func WorkerHandlerOptionMisfirePolicy(policy string) WorkerHandlerOptionFunc {
+	return func(wh *WorkerHandler) {
+		wh.MisfirePolicy = policy
+	}
+}
+
+// WorkerHandlerOptionOverlapPolicy set policy for new run when previous run of job is still running (only for cron worker),
+// policy must one of cronworker.OverlapPolicyAllow (default), cronworker.OverlapPolicySkip, or cronworker.OverlapPolicyQueue
+func WorkerHandlerOptionOverlapPolicy(policy string) WorkerHandlerOptionFunc {
+	return func(wh *WorkerHandler) {
+		wh.OverlapPolicy = policy
+	}
+}
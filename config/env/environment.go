@@ -57,6 +57,27 @@ type Env struct {
 	TaskQueueDashboardPort uint16
 	// TaskQueueDashboardMaxClientSubscribers Config
 	TaskQueueDashboardMaxClientSubscribers int
+	// TaskQueueFileStoragePath Config, used when task queue worker running without redis and database
+	TaskQueueFileStoragePath string
+	// TaskQueueDashboardAuth Config, auth type for dashboard API (basic or bearer), empty means dashboard not protected
+	TaskQueueDashboardAuth string
+	// TaskQueueDashboardReadPermission Config, ACL permission code for read-only role (only for bearer auth)
+	TaskQueueDashboardReadPermission string
+	// TaskQueueDashboardOperatorPermission Config, ACL permission code for operator role (only for bearer auth)
+	TaskQueueDashboardOperatorPermission string
+
+	// CronWorkerTimezone Config, default timezone (IANA name, example: Asia/Jakarta) of cron worker job schedule, empty means local time
+	CronWorkerTimezone string
+	// CronWorkerDashboardPort Config, port of cron worker management REST API, zero means API disabled
+	CronWorkerDashboardPort uint16
+	// CronWorkerDashboardAuth Config, auth type for cron worker management API (basic or bearer), empty means API not protected
+	CronWorkerDashboardAuth string
+	// CronWorkerDashboardReadPermission Config, ACL permission code for read-only role (only for bearer auth)
+	CronWorkerDashboardReadPermission string
+	// CronWorkerDashboardOperatorPermission Config, ACL permission code for operator role (only for bearer auth)
+	CronWorkerDashboardOperatorPermission string
+	// CronWorkerHistoryStore Config, storage for cron worker job run history (mongo, sql, or redis), empty means history not persisted
+	CronWorkerHistoryStore string
 
 	// UseConsul for distributed lock if run in multiple instance
 	UseConsul bool
@@ -164,6 +185,41 @@ func Load(serviceName string) {
 		if env.TaskQueueDashboardPort <= 0 || env.TaskQueueDashboardMaxClientSubscribers > 10 {
 			env.TaskQueueDashboardMaxClientSubscribers = 10 // default
 		}
+		env.TaskQueueFileStoragePath, ok = os.LookupEnv("TASK_QUEUE_FILE_STORAGE_PATH")
+		if !ok {
+			env.TaskQueueFileStoragePath = os.Getenv(candihelper.WORKDIR) + "task_queue_worker.db"
+		}
+		env.TaskQueueDashboardAuth = os.Getenv("TASK_QUEUE_DASHBOARD_AUTH")
+		if env.TaskQueueDashboardAuth != "" && env.TaskQueueDashboardAuth != "basic" && env.TaskQueueDashboardAuth != "bearer" {
+			mErrs.Append("TASK_QUEUE_DASHBOARD_AUTH", errors.New("TASK_QUEUE_DASHBOARD_AUTH environment must basic or bearer"))
+		}
+		env.TaskQueueDashboardReadPermission = os.Getenv("TASK_QUEUE_DASHBOARD_READ_PERMISSION")
+		env.TaskQueueDashboardOperatorPermission = os.Getenv("TASK_QUEUE_DASHBOARD_OPERATOR_PERMISSION")
+	}
+
+	if env.UseCronScheduler {
+		env.CronWorkerTimezone = os.Getenv("CRON_WORKER_TIMEZONE")
+		if _, err := time.LoadLocation(env.CronWorkerTimezone); err != nil {
+			mErrs.Append("CRON_WORKER_TIMEZONE", fmt.Errorf("CRON_WORKER_TIMEZONE environment must valid timezone name: %v", err))
+		}
+		if dashboardPort, ok := os.LookupEnv("CRON_WORKER_DASHBOARD_PORT"); ok && dashboardPort != "" {
+			port, err := strconv.Atoi(dashboardPort)
+			if err != nil {
+				mErrs.Append("CRON_WORKER_DASHBOARD_PORT", errors.New("CRON_WORKER_DASHBOARD_PORT environment must in integer format"))
+			}
+			env.CronWorkerDashboardPort = uint16(port)
+		}
+		env.CronWorkerDashboardAuth = os.Getenv("CRON_WORKER_DASHBOARD_AUTH")
+		if env.CronWorkerDashboardAuth != "" && env.CronWorkerDashboardAuth != "basic" && env.CronWorkerDashboardAuth != "bearer" {
+			mErrs.Append("CRON_WORKER_DASHBOARD_AUTH", errors.New("CRON_WORKER_DASHBOARD_AUTH environment must basic or bearer"))
+		}
+		env.CronWorkerDashboardReadPermission = os.Getenv("CRON_WORKER_DASHBOARD_READ_PERMISSION")
+		env.CronWorkerDashboardOperatorPermission = os.Getenv("CRON_WORKER_DASHBOARD_OPERATOR_PERMISSION")
+		env.CronWorkerHistoryStore = os.Getenv("CRON_WORKER_HISTORY_STORE")
+		if env.CronWorkerHistoryStore != "" && env.CronWorkerHistoryStore != "mongo" &&
+			env.CronWorkerHistoryStore != "sql" && env.CronWorkerHistoryStore != "redis" {
+			mErrs.Append("CRON_WORKER_HISTORY_STORE", errors.New("CRON_WORKER_HISTORY_STORE environment must mongo, sql, or redis"))
+		}
 	}
 
 	env.UseConsul = parseBool("USE_CONSUL")
@@ -3,9 +3,11 @@ module github.com/golangid/candi
 go 1.16
 
 require (
+	github.com/DATA-DOG/go-sqlmock v1.5.0
 	github.com/HdrHistogram/hdrhistogram-go v1.1.0 // indirect
 	github.com/Shopify/sarama v1.29.0
 	github.com/agungdwiprasetyo/task-queue-worker-dashboard/external v0.0.0-20210808151550-cb2477948542
+	github.com/alicebob/miniredis/v2 v2.14.3
 	github.com/go-playground/locales v0.13.0
 	github.com/go-playground/universal-translator v0.17.0
 	github.com/go-playground/validator/v10 v10.6.0
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/codebase/interfaces"
+)
+
+// DashboardAuth config for authentication and authorization of worker dashboard (task queue worker and cron worker)
+// using service middleware. In basic auth all authenticated user has operator role, in bearer auth role is checked
+// from ACL permission code. Nil DashboardAuth means dashboard is not protected
+type DashboardAuth struct {
+	Middleware interfaces.Middleware
+	// AuthType Basic or Bearer, ACL permission only checked in bearer auth
+	AuthType string
+	// ReadPermissionCode ACL permission code for read-only role, optional
+	ReadPermissionCode string
+	// OperatorPermissionCode ACL permission code for operator role (all mutation)
+	OperatorPermissionCode string
+}
+
+// Validate dashboard auth config, dashboard must never served unprotected when auth is set without middleware
+func (d *DashboardAuth) Validate() error {
+	if d.Middleware == nil {
+		return errors.New("dashboard auth require middleware")
+	}
+	if d.AuthType != Basic && d.AuthType != Bearer {
+		return fmt.Errorf(`invalid dashboard auth type "%s" (must "%s" or "%s")`, d.AuthType, Basic, Bearer)
+	}
+	return nil
+}
+
+// PermissionCode get ACL permission code for read-only (operator is false) or operator role
+func (d *DashboardAuth) PermissionCode(operator bool) string {
+	if d == nil {
+		return ""
+	}
+	if operator {
+		return d.OperatorPermissionCode
+	}
+	return d.ReadPermissionCode
+}
+
+// HTTPMiddleware wrap handler with authentication and ACL permission check of given code (skipped if empty)
+func (d *DashboardAuth) HTTPMiddleware(next http.Handler, permissionCode string) http.Handler {
+	if d == nil {
+		return next
+	}
+	if d.AuthType != Bearer {
+		return d.Middleware.HTTPBasicAuth(next)
+	}
+
+	if permissionCode != "" {
+		next = d.Middleware.HTTPPermissionACL(permissionCode)(next)
+	}
+	return d.Middleware.HTTPBearerAuth(next)
+}
+
+// GraphQLMiddlewares get graphql authentication and ACL permission check of given code (skipped if empty)
+func (d *DashboardAuth) GraphQLMiddlewares(permissionCode string) (mws []types.MiddlewareFunc) {
+	if d == nil {
+		return nil
+	}
+	if d.AuthType != Bearer {
+		return append(mws, d.Middleware.GraphQLBasicAuth)
+	}
+
+	mws = append(mws, d.Middleware.GraphQLBearerAuth)
+	if permissionCode != "" {
+		mws = append(mws, d.Middleware.GraphQLPermissionACL(permissionCode))
+	}
+	return mws
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	mockinterfaces "github.com/golangid/candi/mocks/codebase/interfaces"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDashboardAuth(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {})
+
+	var nilAuth *DashboardAuth
+	assert.NotNil(t, nilAuth.HTTPMiddleware(next, nilAuth.PermissionCode(true)))
+	assert.Nil(t, nilAuth.GraphQLMiddlewares(""))
+
+	mw := &mockinterfaces.Middleware{}
+	assert.EqualError(t, (&DashboardAuth{AuthType: Basic}).Validate(), "dashboard auth require middleware")
+	assert.Error(t, (&DashboardAuth{Middleware: mw, AuthType: "digest"}).Validate())
+
+	auth := &DashboardAuth{Middleware: mw, AuthType: Bearer, ReadPermissionCode: "read", OperatorPermissionCode: "operator"}
+	assert.NoError(t, auth.Validate())
+	assert.Equal(t, "read", auth.PermissionCode(false))
+	assert.Equal(t, "operator", auth.PermissionCode(true))
+
+	mw.On("GraphQLPermissionACL", "operator").Return(nil)
+	assert.Len(t, auth.GraphQLMiddlewares("operator"), 2)
+	assert.Len(t, auth.GraphQLMiddlewares(""), 1)
+
+	auth.AuthType = Basic
+	assert.Len(t, auth.GraphQLMiddlewares("operator"), 1)
+}
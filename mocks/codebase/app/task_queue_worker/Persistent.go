@@ -7,6 +7,8 @@ import (
 
 	taskqueueworker "github.com/golangid/candi/codebase/app/task_queue_worker"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // Persistent is an autogenerated mock type for the Persistent type
@@ -30,6 +32,29 @@ func (_m *Persistent) AggregateAllTaskJob(ctx context.Context, filter taskqueuew
 	return r0
 }
 
+// ClaimJob provides a mock function with given fields: ctx, jobID, workerID, leaseUntil
+func (_m *Persistent) ClaimJob(ctx context.Context, jobID string, workerID string, leaseUntil time.Time) (*taskqueueworker.Job, error) {
+	ret := _m.Called(ctx, jobID, workerID, leaseUntil)
+
+	var r0 *taskqueueworker.Job
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time) *taskqueueworker.Job); ok {
+		r0 = rf(ctx, jobID, workerID, leaseUntil)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*taskqueueworker.Job)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, time.Time) error); ok {
+		r1 = rf(ctx, jobID, workerID, leaseUntil)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CleanJob provides a mock function with given fields: ctx, taskName
 func (_m *Persistent) CleanJob(ctx context.Context, taskName string) {
 	_m.Called(ctx, taskName)
@@ -49,6 +74,25 @@ func (_m *Persistent) CountAllJob(ctx context.Context, filter taskqueueworker.Fi
 	return r0
 }
 
+// DeleteJobs provides a mock function with given fields: ctx, ids
+func (_m *Persistent) DeleteJobs(ctx context.Context, ids []string) error {
+	ret := _m.Called(ctx, ids)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) error); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteRecurringJob provides a mock function with given fields: ctx, id
+func (_m *Persistent) DeleteRecurringJob(ctx context.Context, id string) {
+	_m.Called(ctx, id)
+}
+
 // FindAllJob provides a mock function with given fields: ctx, filter
 func (_m *Persistent) FindAllJob(ctx context.Context, filter taskqueueworker.Filter) []taskqueueworker.Job {
 	ret := _m.Called(ctx, filter)
@@ -65,6 +109,61 @@ func (_m *Persistent) FindAllJob(ctx context.Context, filter taskqueueworker.Fil
 	return r0
 }
 
+// FindAllPausedTask provides a mock function with given fields: ctx
+func (_m *Persistent) FindAllPausedTask(ctx context.Context) []string {
+	ret := _m.Called(ctx)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(context.Context) []string); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	return r0
+}
+
+// FindAllRecurringJob provides a mock function with given fields: ctx, taskName
+func (_m *Persistent) FindAllRecurringJob(ctx context.Context, taskName string) []taskqueueworker.RecurringJob {
+	ret := _m.Called(ctx, taskName)
+
+	var r0 []taskqueueworker.RecurringJob
+	if rf, ok := ret.Get(0).(func(context.Context, string) []taskqueueworker.RecurringJob); ok {
+		r0 = rf(ctx, taskName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]taskqueueworker.RecurringJob)
+		}
+	}
+
+	return r0
+}
+
+// FindBatchByID provides a mock function with given fields: ctx, id
+func (_m *Persistent) FindBatchByID(ctx context.Context, id string) (*taskqueueworker.Batch, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *taskqueueworker.Batch
+	if rf, ok := ret.Get(0).(func(context.Context, string) *taskqueueworker.Batch); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*taskqueueworker.Batch)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindJobByID provides a mock function with given fields: ctx, id
 func (_m *Persistent) FindJobByID(ctx context.Context, id string) (*taskqueueworker.Job, error) {
 	ret := _m.Called(ctx, id)
@@ -88,12 +187,118 @@ func (_m *Persistent) FindJobByID(ctx context.Context, id string) (*taskqueuewor
 	return r0, r1
 }
 
+// FindRecurringJobByID provides a mock function with given fields: ctx, id
+func (_m *Persistent) FindRecurringJobByID(ctx context.Context, id string) (*taskqueueworker.RecurringJob, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *taskqueueworker.RecurringJob
+	if rf, ok := ret.Get(0).(func(context.Context, string) *taskqueueworker.RecurringJob); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*taskqueueworker.RecurringJob)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RenewJobLease provides a mock function with given fields: ctx, jobID, workerID, leaseUntil
+func (_m *Persistent) RenewJobLease(ctx context.Context, jobID string, workerID string, leaseUntil time.Time) error {
+	ret := _m.Called(ctx, jobID, workerID, leaseUntil)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time) error); ok {
+		r0 = rf(ctx, jobID, workerID, leaseUntil)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveBatch provides a mock function with given fields: ctx, batch
+func (_m *Persistent) SaveBatch(ctx context.Context, batch *taskqueueworker.Batch) {
+	_m.Called(ctx, batch)
+}
+
 // SaveJob provides a mock function with given fields: ctx, job
 func (_m *Persistent) SaveJob(ctx context.Context, job *taskqueueworker.Job) {
 	_m.Called(ctx, job)
 }
 
+// SaveJobs provides a mock function with given fields: ctx, jobs
+func (_m *Persistent) SaveJobs(ctx context.Context, jobs []*taskqueueworker.Job) {
+	_m.Called(ctx, jobs)
+}
+
+// SaveRecurringJob provides a mock function with given fields: ctx, recurringJob
+func (_m *Persistent) SaveRecurringJob(ctx context.Context, recurringJob *taskqueueworker.RecurringJob) {
+	_m.Called(ctx, recurringJob)
+}
+
+// SaveUniqueJob provides a mock function with given fields: ctx, job, createdAfter
+func (_m *Persistent) SaveUniqueJob(ctx context.Context, job *taskqueueworker.Job, createdAfter time.Time) (*taskqueueworker.Job, error) {
+	ret := _m.Called(ctx, job, createdAfter)
+
+	var r0 *taskqueueworker.Job
+	if rf, ok := ret.Get(0).(func(context.Context, *taskqueueworker.Job, time.Time) *taskqueueworker.Job); ok {
+		r0 = rf(ctx, job, createdAfter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*taskqueueworker.Job)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *taskqueueworker.Job, time.Time) error); ok {
+		r1 = rf(ctx, job, createdAfter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetTaskPaused provides a mock function with given fields: ctx, taskName, isPaused
+func (_m *Persistent) SetTaskPaused(ctx context.Context, taskName string, isPaused bool) {
+	_m.Called(ctx, taskName, isPaused)
+}
+
 // UpdateAllStatus provides a mock function with given fields: ctx, taskName, currentStatus, updatedStatus
 func (_m *Persistent) UpdateAllStatus(ctx context.Context, taskName string, currentStatus []taskqueueworker.JobStatusEnum, updatedStatus taskqueueworker.JobStatusEnum) {
 	_m.Called(ctx, taskName, currentStatus, updatedStatus)
 }
+
+// UpdateJobProgress provides a mock function with given fields: ctx, jobID, workerID, progress, message
+func (_m *Persistent) UpdateJobProgress(ctx context.Context, jobID string, workerID string, progress int, message string) {
+	_m.Called(ctx, jobID, workerID, progress, message)
+}
+
+// UpdateRecurringJobNextRun provides a mock function with given fields: ctx, recurringJob, prevNextRunAt
+func (_m *Persistent) UpdateRecurringJobNextRun(ctx context.Context, recurringJob *taskqueueworker.RecurringJob, prevNextRunAt time.Time) (bool, error) {
+	ret := _m.Called(ctx, recurringJob, prevNextRunAt)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, *taskqueueworker.RecurringJob, time.Time) bool); ok {
+		r0 = rf(ctx, recurringJob, prevNextRunAt)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *taskqueueworker.RecurringJob, time.Time) error); ok {
+		r1 = rf(ctx, recurringJob, prevNextRunAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -49,3 +49,8 @@ func (_m *QueueStorage) PopJob(taskName string) string {
 func (_m *QueueStorage) PushJob(job *taskqueueworker.Job) {
 	_m.Called(job)
 }
+
+// PushJobs provides a mock function with given fields: jobs
+func (_m *QueueStorage) PushJobs(jobs []*taskqueueworker.Job) {
+	_m.Called(jobs)
+}